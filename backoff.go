@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"time"
+)
+
+// Exponential delays between attempts
+type backoff struct {
+	delay    time.Duration
+	minDelay time.Duration
+	maxDelay time.Duration
+}
+
+func newBackoff(minDelay, maxDelay time.Duration) *backoff {
+	return &backoff{delay: minDelay, minDelay: minDelay, maxDelay: maxDelay}
+}
+
+// wait sleeps for the current delay, returning false if the context was cancelled first
+func (b *backoff) wait(ctx context.Context) bool {
+	timer := time.NewTimer(b.delay)
+	defer timer.Stop()
+	b.delay = min(2*b.delay, b.maxDelay)
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (b *backoff) reset() {
+	b.delay = b.minDelay
+}
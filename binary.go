@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/binary"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
+)
+
+// Increment implements binary.Handler.
+func (h *Handler) Increment(ctx context.Context, id string, delta uint64, options *binary.CounterOptions) (*wrpc.Result[binary.CounterResult, binary.CounterError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	incrementOptions, err := IncrementOptions(delta, options)
+	if err != nil {
+		return wrpc.Err[binary.CounterResult](*binary.NewCounterErrorInvalidArgument(err.Error())), nil
+	}
+	result, err := collection.Binary().Increment(id, incrementOptions)
+	if err != nil {
+		h.Logger.Error("Error incrementing counter", "error", err)
+		if docErr := DocumentError(err); docErr != nil {
+			return wrpc.Err[binary.CounterResult](*binary.NewCounterErrorDocumentError(docErr)), nil
+		}
+		return nil, err
+	}
+	return wrpc.Ok[binary.CounterError](CounterResult(result)), nil
+}
+
+// Decrement implements binary.Handler.
+func (h *Handler) Decrement(ctx context.Context, id string, delta uint64, options *binary.CounterOptions) (*wrpc.Result[binary.CounterResult, binary.CounterError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	decrementOptions, err := DecrementOptions(delta, options)
+	if err != nil {
+		return wrpc.Err[binary.CounterResult](*binary.NewCounterErrorInvalidArgument(err.Error())), nil
+	}
+	result, err := collection.Binary().Decrement(id, decrementOptions)
+	if err != nil {
+		h.Logger.Error("Error decrementing counter", "error", err)
+		if docErr := DocumentError(err); docErr != nil {
+			return wrpc.Err[binary.CounterResult](*binary.NewCounterErrorDocumentError(docErr)), nil
+		}
+		return nil, err
+	}
+	return wrpc.Ok[binary.CounterError](CounterResult(result)), nil
+}
+
+// Append implements binary.Handler.
+func (h *Handler) Append(ctx context.Context, id string, value []byte, options *binary.BinaryWriteOptions) (*wrpc.Result[types.MutationMetadata, types.DocumentError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	result, err := collection.Binary().Append(id, value, AppendOptions(options))
+	if err != nil {
+		h.Logger.Error("Error appending to document", "error", err)
+		if docErr := DocumentError(err); docErr != nil {
+			return Err[types.MutationMetadata](*docErr), nil
+		}
+		return nil, err
+	}
+	return Ok(MutationMetadata(result)), nil
+}
+
+// Prepend implements binary.Handler.
+func (h *Handler) Prepend(ctx context.Context, id string, value []byte, options *binary.BinaryWriteOptions) (*wrpc.Result[types.MutationMetadata, types.DocumentError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	result, err := collection.Binary().Prepend(id, value, PrependOptions(options))
+	if err != nil {
+		h.Logger.Error("Error prepending to document", "error", err)
+		if docErr := DocumentError(err); docErr != nil {
+			return Err[types.MutationMetadata](*docErr), nil
+		}
+		return nil, err
+	}
+	return Ok(MutationMetadata(result)), nil
+}
@@ -0,0 +1,760 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/google/uuid"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/blobstore/blobstore"
+)
+
+const (
+	// Objects are split into chunk documents of this size, well below the 20MB document size limit
+	blobChunkSize = 1 << 20
+	// Maximum number of object names sent at once when listing a container
+	blobListBatchSize = 1000
+	// Containers, manifests and chunks are stored in the blobstore collection of the link under this prefix
+	blobIdPrefix = "_blobstore::"
+	// How long the chunks of a replaced or deleted object are kept, so that reads that started before
+	// the object changed can complete
+	blobChunkGracePeriod = 10 * time.Minute
+)
+
+var (
+	errBlobContainerNotFound = errors.New("container does not exist")
+	errBlobContainerExists   = errors.New("container already exists")
+	errBlobContainerName     = errors.New("container names must not be empty or contain '/'")
+	errBlobObjectNotFound    = errors.New("object does not exist")
+	errBlobChecksumMismatch  = errors.New("object data does not match its checksum")
+	errBlobInvalidRange      = errors.New("range is outside of the object")
+	errBlobReadClosed        = errors.New("object data was closed before it was fully read")
+	errBlobDataUnreadable    = errors.New("object data could not be read")
+)
+
+// A container, stored as a marker document
+type blobContainer struct {
+	CreatedAt uint64 `json:"createdAt"`
+}
+
+// An object, stored as a manifest document listing the chunk documents holding its data
+type blobManifest struct {
+	CreatedAt uint64 `json:"createdAt"`
+	Size      uint64 `json:"size"`
+	// Chunks are written under a new generation by every write, so replacing an object only
+	// takes effect once its manifest is swapped
+	Generation string `json:"generation"`
+	ChunkSize  uint64 `json:"chunkSize"`
+	// Hex encoded SHA-256 checksum of each chunk, verified when the chunk is read
+	Checksums []string `json:"checksums"`
+}
+
+// The part of a chunk that falls within a range of an object
+type blobChunkRange struct {
+	index    int
+	from, to uint64
+}
+
+// Blob storage on top of a collection
+type blobStore struct {
+	collection *gocb.Collection
+	// Slots of the bulk operations of the link, which objects are deleted with
+	bulkSlots chan struct{}
+}
+
+func blobContainerId(container string) string {
+	return blobIdPrefix + "container::" + container
+}
+
+func blobObjectPrefix(container string) string {
+	return blobIdPrefix + "object::" + container + "/"
+}
+
+func blobObjectId(container, object string) string {
+	return blobObjectPrefix(container) + object
+}
+
+func blobChunkId(generation string, index int) string {
+	return blobIdPrefix + "chunk::" + generation + "::" + strconv.Itoa(index)
+}
+
+func blobChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// blobRange returns the parts of the chunks of an object of the given size that hold the bytes
+// between the start and end offsets (inclusive), reading up to the end of the object
+func blobRange(size, chunkSize, start, end uint64) ([]blobChunkRange, error) {
+	if size == 0 && start == 0 {
+		return nil, nil
+	}
+	end = min(end, size-1)
+	if start >= size || start > end {
+		return nil, errBlobInvalidRange
+	}
+	var ranges []blobChunkRange
+	for index := start / chunkSize; index <= end/chunkSize; index++ {
+		chunkStart := index * chunkSize
+		ranges = append(ranges, blobChunkRange{
+			index: int(index),
+			from:  max(start, chunkStart) - chunkStart,
+			to:    min(end+1, chunkStart+chunkSize) - chunkStart,
+		})
+	}
+	return ranges, nil
+}
+
+func validBlobContainerName(name string) error {
+	if name == "" || strings.Contains(name, "/") {
+		return errBlobContainerName
+	}
+	return nil
+}
+
+func (b *blobStore) createContainer(name string) error {
+	if err := validBlobContainerName(name); err != nil {
+		return err
+	}
+	_, err := b.collection.Insert(blobContainerId(name), blobContainer{CreatedAt: uint64(time.Now().Unix())}, nil)
+	if errors.Is(err, gocb.ErrDocumentExists) {
+		return errBlobContainerExists
+	}
+	return err
+}
+
+func (b *blobStore) container(name string) (*blobContainer, error) {
+	if err := validBlobContainerName(name); err != nil {
+		return nil, err
+	}
+	result, err := b.collection.Get(blobContainerId(name), nil)
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return nil, errBlobContainerNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var container blobContainer
+	if err := result.Content(&container); err != nil {
+		return nil, err
+	}
+	return &container, nil
+}
+
+// deleteContainer removes a container along with all of its objects
+func (b *blobStore) deleteContainer(ctx context.Context, name string) error {
+	if err := b.clearContainer(ctx, name); err != nil {
+		return err
+	}
+	_, err := b.collection.Remove(blobContainerId(name), nil)
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return errBlobContainerNotFound
+	}
+	return err
+}
+
+// clearContainer removes all objects of a container
+func (b *blobStore) clearContainer(ctx context.Context, name string) error {
+	if _, err := b.container(name); err != nil {
+		return err
+	}
+	objects, err := b.listObjects(name)
+	if err != nil {
+		return err
+	}
+	defer objects.close()
+	for {
+		names, done, err := objects.next(blobListBatchSize)
+		if err != nil {
+			return err
+		}
+		if err := b.deleteObjects(ctx, name, names); err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+// listObjects scans the manifests of the objects of a container
+func (b *blobStore) listObjects(container string) (*blobObjectNames, error) {
+	prefix := blobObjectPrefix(container)
+	result, err := b.collection.Scan(gocb.NewRangeScanForPrefix(prefix), &gocb.ScanOptions{IDsOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return &blobObjectNames{prefix: prefix, result: result}, nil
+}
+
+func (b *blobStore) manifest(container, object string) (*blobManifest, error) {
+	manifest, _, err := b.versionedManifest(container, object)
+	return manifest, err
+}
+
+// versionedManifest reads the manifest of an object, along with the CAS it can be replaced or removed with
+func (b *blobStore) versionedManifest(container, object string) (*blobManifest, gocb.Cas, error) {
+	result, err := b.collection.Get(blobObjectId(container, object), nil)
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return nil, 0, errBlobObjectNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	var manifest blobManifest
+	if err := result.Content(&manifest); err != nil {
+		return nil, 0, err
+	}
+	return &manifest, result.Cas(), nil
+}
+
+// chunk reads a chunk of an object, verifying its checksum
+func (b *blobStore) chunk(manifest *blobManifest, index int) ([]byte, error) {
+	result, err := b.collection.Get(blobChunkId(manifest.Generation, index), &gocb.GetOptions{
+		Transcoder: gocb.NewRawBinaryTranscoder(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read chunk %d: %w", index, err)
+	}
+	var data []byte
+	if err := result.Content(&data); err != nil {
+		return nil, err
+	}
+	if index >= len(manifest.Checksums) || blobChecksum(data) != manifest.Checksums[index] {
+		return nil, fmt.Errorf("%w (chunk %d)", errBlobChecksumMismatch, index)
+	}
+	return data, nil
+}
+
+// write stores the data of an object, replacing any previous version once fully written
+func (b *blobStore) write(container, object string, data io.Reader) error {
+	manifest := &blobManifest{
+		CreatedAt:  uint64(time.Now().Unix()),
+		Generation: uuid.NewString(),
+		ChunkSize:  blobChunkSize,
+	}
+	buf := make([]byte, blobChunkSize)
+	for {
+		n, err := io.ReadFull(data, buf)
+		if n > 0 {
+			if err := b.writeChunk(manifest, buf[:n]); err != nil {
+				b.removeChunks(manifest)
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+		if err != nil {
+			b.removeChunks(manifest)
+			return fmt.Errorf("%w: %w", errBlobDataUnreadable, err)
+		}
+	}
+	return b.swapManifest(container, object, manifest)
+}
+
+// copy stores a copy of the data of an object under another object
+func (b *blobStore) copy(src *blobManifest, container, object string) error {
+	manifest := &blobManifest{
+		CreatedAt:  uint64(time.Now().Unix()),
+		Generation: uuid.NewString(),
+		ChunkSize:  src.ChunkSize,
+	}
+	for index := range src.Checksums {
+		data, err := b.chunk(src, index)
+		if err == nil {
+			err = b.writeChunk(manifest, data)
+		}
+		if err != nil {
+			b.removeChunks(manifest)
+			return err
+		}
+	}
+	return b.swapManifest(container, object, manifest)
+}
+
+// writeChunk appends a chunk to an object that is being written
+func (b *blobStore) writeChunk(manifest *blobManifest, data []byte) error {
+	_, err := b.collection.Upsert(blobChunkId(manifest.Generation, len(manifest.Checksums)), data, &gocb.UpsertOptions{
+		Transcoder: gocb.NewRawBinaryTranscoder(),
+	})
+	if err != nil {
+		return err
+	}
+	manifest.Checksums = append(manifest.Checksums, blobChecksum(data))
+	manifest.Size += uint64(len(data))
+	return nil
+}
+
+// swapManifest makes a written object visible, expiring the chunks of the version it replaces once it is swapped.
+// The manifest is only swapped if it did not change since it was read, so that the chunks expired are the ones
+// of the version that was replaced, and is read again if another write or delete of the object swapped it first
+func (b *blobStore) swapManifest(container, object string, manifest *blobManifest) error {
+	id := blobObjectId(container, object)
+	for {
+		previous, cas, err := b.versionedManifest(container, object)
+		switch {
+		case errors.Is(err, errBlobObjectNotFound):
+			_, err = b.collection.Insert(id, manifest, nil)
+		case err == nil:
+			_, err = b.collection.Replace(id, manifest, &gocb.ReplaceOptions{Cas: cas})
+		}
+		if isBlobManifestSwapped(err) {
+			continue
+		}
+		if err != nil {
+			b.removeChunks(manifest)
+			return err
+		}
+		if previous != nil {
+			b.expireChunks(previous)
+		}
+		return nil
+	}
+}
+
+// deleteObject removes an object, doing nothing if the object does not exist
+func (b *blobStore) deleteObject(container, object string) error {
+	for {
+		manifest, cas, err := b.versionedManifest(container, object)
+		if errors.Is(err, errBlobObjectNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		_, err = b.collection.Remove(blobObjectId(container, object), &gocb.RemoveOptions{Cas: cas})
+		if isBlobManifestSwapped(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		b.expireChunks(manifest)
+		return nil
+	}
+}
+
+// Errors of manifest swaps that lost a race with another write or delete of the object
+func isBlobManifestSwapped(err error) bool {
+	return errors.Is(err, gocb.ErrCasMismatch) || errors.Is(err, gocb.ErrDocumentExists) || errors.Is(err, gocb.ErrDocumentNotFound)
+}
+
+func (b *blobStore) deleteObjects(ctx context.Context, container string, objects []string) error {
+	errs, err := bulk(ctx, len(objects), b.bulkSlots, func(idx int) error {
+		return b.deleteObject(container, objects[idx])
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(errs...)
+}
+
+// removeChunks removes the chunks of an object that was never visible, on a best effort basis
+func (b *blobStore) removeChunks(manifest *blobManifest) {
+	for index := range manifest.Checksums {
+		_, _ = b.collection.Remove(blobChunkId(manifest.Generation, index), nil)
+	}
+}
+
+// expireChunks expires the chunks of an object that is no longer referenced after blobChunkGracePeriod,
+// on a best effort basis
+func (b *blobStore) expireChunks(manifest *blobManifest) {
+	for index := range manifest.Checksums {
+		_, _ = b.collection.Touch(blobChunkId(manifest.Generation, index), blobChunkGracePeriod, nil)
+	}
+}
+
+// The names of the objects of a container, read in batches
+type blobObjectNames struct {
+	prefix string
+	result *gocb.ScanResult
+}
+
+// next reads up to max names, reporting whether all names have been read
+func (n *blobObjectNames) next(max int) ([]string, bool, error) {
+	names := make([]string, 0, max)
+	for len(names) < max {
+		item := n.result.Next()
+		if item == nil {
+			return names, true, n.result.Err()
+		}
+		names = append(names, strings.TrimPrefix(item.ID(), n.prefix))
+	}
+	return names, false, nil
+}
+
+func (n *blobObjectNames) close() {
+	_ = n.result.Close()
+}
+
+// The outcome of streaming object names or data, received once the stream ended
+type blobFuture struct {
+	once sync.Once
+	done chan struct{}
+	err  error
+}
+
+func newBlobFuture() *blobFuture {
+	return &blobFuture{done: make(chan struct{})}
+}
+
+// resolve sets the outcome of the stream, only the first outcome is kept
+func (f *blobFuture) resolve(err error) {
+	f.once.Do(func() {
+		f.err = err
+		close(f.done)
+	})
+}
+
+// Receive implements wrpc.Receiver.
+func (f *blobFuture) Receive() (*wrpc.Result[struct{}, string], error) {
+	<-f.done
+	if f.err != nil {
+		return wrpc.Err[struct{}](f.err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// objectNames reads the names of all objects of a container, in lexicographic order so that pages of them are stable
+func (b *blobStore) objectNames(container string) ([]string, error) {
+	objects, err := b.listObjects(container)
+	if err != nil {
+		return nil, err
+	}
+	defer objects.close()
+	var names []string
+	for {
+		batch, done, err := objects.next(blobListBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, batch...)
+		if done {
+			slices.Sort(names)
+			return names, nil
+		}
+	}
+}
+
+// Streams the names of the objects of a container in batches
+type blobNameStream struct {
+	names  []string
+	result *blobFuture
+}
+
+// Receive implements wrpc.Receiver.
+func (s *blobNameStream) Receive() ([]string, error) {
+	if len(s.names) == 0 {
+		s.result.resolve(nil)
+		return nil, io.EOF
+	}
+	batch := s.names[:min(blobListBatchSize, len(s.names))]
+	s.names = s.names[len(batch):]
+	return batch, nil
+}
+
+// Streams a range of the data of an object, one chunk at a time
+type blobDataStream struct {
+	store    *blobStore
+	manifest *blobManifest
+	ranges   []blobChunkRange
+	pending  []byte
+	result   *blobFuture
+}
+
+// Read implements io.Reader.
+func (s *blobDataStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if len(s.ranges) == 0 {
+			s.result.resolve(nil)
+			return 0, io.EOF
+		}
+		chunk, err := s.store.chunk(s.manifest, s.ranges[0].index)
+		if err != nil {
+			s.result.resolve(err)
+			return 0, err
+		}
+		s.pending = chunk[s.ranges[0].from:s.ranges[0].to]
+		s.ranges = s.ranges[1:]
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Close implements io.Closer.
+func (s *blobDataStream) Close() error {
+	if len(s.ranges) > 0 || len(s.pending) > 0 {
+		s.result.resolve(errBlobReadClosed)
+	}
+	s.result.resolve(nil)
+	return nil
+}
+
+// getBlobStoreFromContext returns the blob storage of the link the invocation was made on
+func (h *Handler) getBlobStoreFromContext(ctx context.Context) (*blobStore, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	return &blobStore{collection: connection.BlobstoreCollection, bulkSlots: connection.bulkSlots}, nil
+}
+
+// ClearContainer implements blobstore.Handler.
+func (h *Handler) ClearContainer(ctx context.Context, name string) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.clearContainer(ctx, name); err != nil {
+		h.Logger.Error("Error clearing container", "container", name, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// ContainerExists implements blobstore.Handler.
+func (h *Handler) ContainerExists(ctx context.Context, name string) (*wrpc.Result[bool, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, err = store.container(name)
+	if errors.Is(err, errBlobContainerNotFound) {
+		return wrpc.Ok[string](false), nil
+	}
+	if err != nil {
+		h.Logger.Error("Error getting container", "container", name, "error", err)
+		return wrpc.Err[bool](err.Error()), nil
+	}
+	return wrpc.Ok[string](true), nil
+}
+
+// CreateContainer implements blobstore.Handler.
+func (h *Handler) CreateContainer(ctx context.Context, name string) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.createContainer(name); err != nil {
+		h.Logger.Error("Error creating container", "container", name, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// DeleteContainer implements blobstore.Handler.
+func (h *Handler) DeleteContainer(ctx context.Context, name string) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.deleteContainer(ctx, name); err != nil {
+		h.Logger.Error("Error deleting container", "container", name, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// GetContainerInfo implements blobstore.Handler.
+func (h *Handler) GetContainerInfo(ctx context.Context, name string) (*wrpc.Result[blobstore.ContainerMetadata, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	container, err := store.container(name)
+	if err != nil {
+		h.Logger.Error("Error getting container", "container", name, "error", err)
+		return wrpc.Err[blobstore.ContainerMetadata](err.Error()), nil
+	}
+	return wrpc.Ok[string](blobstore.ContainerMetadata{CreatedAt: container.CreatedAt}), nil
+}
+
+// ListContainerObjects implements blobstore.Handler.
+func (h *Handler) ListContainerObjects(ctx context.Context, name string, limit *uint64, offset *uint64) (*wrpc.Result[wrpc.Tuple2[wrpc.Receiver[[]string], wrpc.Receiver[*wrpc.Result[struct{}, string]]], string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.container(name); err != nil {
+		h.Logger.Error("Error getting container", "container", name, "error", err)
+		return wrpc.Err[wrpc.Tuple2[wrpc.Receiver[[]string], wrpc.Receiver[*wrpc.Result[struct{}, string]]]](err.Error()), nil
+	}
+	names, err := store.objectNames(name)
+	if err != nil {
+		h.Logger.Error("Error listing objects", "container", name, "error", err)
+		return wrpc.Err[wrpc.Tuple2[wrpc.Receiver[[]string], wrpc.Receiver[*wrpc.Result[struct{}, string]]]](err.Error()), nil
+	}
+	if offset != nil {
+		names = names[min(*offset, uint64(len(names))):]
+	}
+	if limit != nil {
+		names = names[:min(*limit, uint64(len(names)))]
+	}
+
+	stream := &blobNameStream{names: names, result: newBlobFuture()}
+	return wrpc.Ok[string](wrpc.Tuple2[wrpc.Receiver[[]string], wrpc.Receiver[*wrpc.Result[struct{}, string]]]{
+		V0: stream,
+		V1: stream.result,
+	}), nil
+}
+
+// CopyObject implements blobstore.Handler.
+func (h *Handler) CopyObject(ctx context.Context, src *blobstore.ObjectId, dest *blobstore.ObjectId) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.copyObject(store, src, dest); err != nil {
+		h.Logger.Error("Error copying object", "src", src, "dest", dest, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// DeleteObject implements blobstore.Handler.
+func (h *Handler) DeleteObject(ctx context.Context, id *blobstore.ObjectId) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.deleteObject(id.Container, id.Object); err != nil {
+		h.Logger.Error("Error deleting object", "id", id, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// DeleteObjects implements blobstore.Handler.
+func (h *Handler) DeleteObjects(ctx context.Context, container string, objects []string) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.deleteObjects(ctx, container, objects); err != nil {
+		h.Logger.Error("Error deleting objects", "container", container, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// GetContainerData implements blobstore.Handler.
+func (h *Handler) GetContainerData(ctx context.Context, id *blobstore.ObjectId, start uint64, end uint64) (*wrpc.Result[wrpc.Tuple2[io.ReadCloser, wrpc.Receiver[*wrpc.Result[struct{}, string]]], string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := store.manifest(id.Container, id.Object)
+	if err != nil {
+		h.Logger.Error("Error getting object", "id", id, "error", err)
+		return wrpc.Err[wrpc.Tuple2[io.ReadCloser, wrpc.Receiver[*wrpc.Result[struct{}, string]]]](err.Error()), nil
+	}
+	ranges, err := blobRange(manifest.Size, manifest.ChunkSize, start, end)
+	if err != nil {
+		return wrpc.Err[wrpc.Tuple2[io.ReadCloser, wrpc.Receiver[*wrpc.Result[struct{}, string]]]](err.Error()), nil
+	}
+
+	stream := &blobDataStream{store: store, manifest: manifest, ranges: ranges, result: newBlobFuture()}
+	return wrpc.Ok[string](wrpc.Tuple2[io.ReadCloser, wrpc.Receiver[*wrpc.Result[struct{}, string]]]{
+		V0: stream,
+		V1: stream.result,
+	}), nil
+}
+
+// GetObjectInfo implements blobstore.Handler.
+func (h *Handler) GetObjectInfo(ctx context.Context, id *blobstore.ObjectId) (*wrpc.Result[blobstore.ObjectMetadata, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := store.manifest(id.Container, id.Object)
+	if err != nil {
+		h.Logger.Error("Error getting object", "id", id, "error", err)
+		return wrpc.Err[blobstore.ObjectMetadata](err.Error()), nil
+	}
+	return wrpc.Ok[string](blobstore.ObjectMetadata{CreatedAt: manifest.CreatedAt, Size: manifest.Size}), nil
+}
+
+// HasObject implements blobstore.Handler.
+func (h *Handler) HasObject(ctx context.Context, id *blobstore.ObjectId) (*wrpc.Result[bool, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := store.collection.Exists(blobObjectId(id.Container, id.Object), nil)
+	if err != nil {
+		h.Logger.Error("Error checking existence of object", "id", id, "error", err)
+		return wrpc.Err[bool](err.Error()), nil
+	}
+	return wrpc.Ok[string](result.Exists()), nil
+}
+
+// MoveObject implements blobstore.Handler.
+func (h *Handler) MoveObject(ctx context.Context, src *blobstore.ObjectId, dest *blobstore.ObjectId) (*wrpc.Result[struct{}, string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if *src == *dest {
+		return wrpc.Ok[string](struct{}{}), nil
+	}
+	if err := h.copyObject(store, src, dest); err != nil {
+		h.Logger.Error("Error copying object", "src", src, "dest", dest, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	if err := store.deleteObject(src.Container, src.Object); err != nil {
+		h.Logger.Error("Error deleting moved object", "id", src, "error", err)
+		return wrpc.Err[struct{}](err.Error()), nil
+	}
+	return wrpc.Ok[string](struct{}{}), nil
+}
+
+// WriteContainerData implements blobstore.Handler.
+func (h *Handler) WriteContainerData(ctx context.Context, id *blobstore.ObjectId, data io.ReadCloser) (*wrpc.Result[wrpc.Receiver[*wrpc.Result[struct{}, string]], string], error) {
+	store, err := h.getBlobStoreFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := store.container(id.Container); err != nil {
+		h.Logger.Error("Error getting container", "container", id.Container, "error", err)
+		return wrpc.Err[wrpc.Receiver[*wrpc.Result[struct{}, string]]](err.Error()), nil
+	}
+
+	// The data is streamed after the invocation returns, the outcome of the write is received separately
+	result := newBlobFuture()
+	go func() {
+		defer data.Close()
+		err := store.write(id.Container, id.Object, data)
+		if err != nil {
+			h.Logger.Error("Error writing object", "id", id, "error", err)
+		}
+		result.resolve(err)
+	}()
+	return wrpc.Ok[string](wrpc.Receiver[*wrpc.Result[struct{}, string]](result)), nil
+}
+
+// copyObject copies an object into an existing container
+func (h *Handler) copyObject(store *blobStore, src *blobstore.ObjectId, dest *blobstore.ObjectId) error {
+	manifest, err := store.manifest(src.Container, src.Object)
+	if err != nil {
+		return err
+	}
+	if _, err := store.container(dest.Container); err != nil {
+		return err
+	}
+	return store.copy(manifest, dest.Container, dest.Object)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+func TestBlobRange(t *testing.T) {
+	cases := []struct {
+		name       string
+		size       uint64
+		start, end uint64
+		expected   []blobChunkRange
+	}{
+		{"empty object", 0, 0, 0, nil},
+		{"within a chunk", 25, 2, 5, []blobChunkRange{{index: 0, from: 2, to: 6}}},
+		{"across chunks", 25, 8, 21, []blobChunkRange{{index: 0, from: 8, to: 10}, {index: 1, from: 0, to: 10}, {index: 2, from: 0, to: 2}}},
+		{"truncated to the object", 25, 20, ^uint64(0), []blobChunkRange{{index: 2, from: 0, to: 5}}},
+	}
+	for _, c := range cases {
+		actual, err := blobRange(c.size, 10, c.start, c.end)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", c.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.name, c.expected, actual)
+		}
+	}
+
+	invalid := []struct {
+		size       uint64
+		start, end uint64
+	}{{25, 25, 30}, {25, 10, 5}, {0, 1, 1}}
+	for _, c := range invalid {
+		if _, err := blobRange(c.size, 10, c.start, c.end); !errors.Is(err, errBlobInvalidRange) {
+			t.Errorf("expected range %d-%d of a %d byte object to be invalid, got %v", c.start, c.end, c.size, err)
+		}
+	}
+}
+
+func TestBlobContainerName(t *testing.T) {
+	for _, name := range []string{"", "a/b"} {
+		if err := validBlobContainerName(name); !errors.Is(err, errBlobContainerName) {
+			t.Errorf("expected container name '%s' to be invalid, got %v", name, err)
+		}
+	}
+	if err := validBlobContainerName("photos"); err != nil {
+		t.Errorf("expected container name to be valid, got %v", err)
+	}
+}
+
+func TestBlobManifestSwapped(t *testing.T) {
+	for _, err := range []error{gocb.ErrCasMismatch, gocb.ErrDocumentExists, gocb.ErrDocumentNotFound} {
+		if !isBlobManifestSwapped(fmt.Errorf("swap failed: %w", err)) {
+			t.Errorf("expected %v to be retried", err)
+		}
+	}
+	if isBlobManifestSwapped(gocb.ErrTimeout) {
+		t.Errorf("expected %v not to be retried", gocb.ErrTimeout)
+	}
+}
+
+func TestBlobNameStream(t *testing.T) {
+	names := make([]string, blobListBatchSize+1)
+	for idx := range names {
+		names[idx] = fmt.Sprintf("object-%05d", idx)
+	}
+	stream := &blobNameStream{names: names, result: newBlobFuture()}
+	var received []string
+	for {
+		batch, err := stream.Receive()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if len(batch) > blobListBatchSize {
+			t.Fatalf("expected at most %d names per batch, got %d", blobListBatchSize, len(batch))
+		}
+		received = append(received, batch...)
+	}
+	if !reflect.DeepEqual(received, names) {
+		t.Errorf("expected every name to be received in order, got %d names", len(received))
+	}
+	if result, _ := stream.result.Receive(); result.Err != nil {
+		t.Errorf("expected the stream to end successfully, got %v", *result.Err)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/document"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
+)
+
+// Per-document results of a bulk operation, in the order the documents were specified
+type GetManyResults = []*wrpc.Result[document.DocumentGetResult, types.DocumentError]
+type MutateManyResults = []*wrpc.Result[types.MutationMetadata, types.DocumentError]
+
+// GetMany implements document.Handler.
+func (h *Handler) GetMany(ctx context.Context, ids []string, options *document.DocumentGetOptions) (GetManyResults, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	return bulk(ctx, len(ids), connection.bulkSlots, func(idx int) *wrpc.Result[document.DocumentGetResult, types.DocumentError] {
+		result, err := connection.Collection.Get(ids[idx], GetOptions(options))
+		if err != nil {
+			h.Logger.Error("Error getting document", "id", ids[idx], "error", err)
+			return Err[document.DocumentGetResult](bulkDocumentError(err, types.NewDocumentErrorNotFound()))
+		}
+		documentResult, err := GetResult(result)
+		if err != nil {
+			h.Logger.Error("Error getting document result", "id", ids[idx], "error", err)
+			return Err[document.DocumentGetResult](*types.NewDocumentErrorNotJson())
+		}
+		return Ok(documentResult)
+	})
+}
+
+// InsertMany implements document.Handler.
+func (h *Handler) InsertMany(ctx context.Context, docs []*wrpc.Tuple2[string, *types.Document], options *document.DocumentInsertOptions) (MutateManyResults, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	return bulk(ctx, len(docs), connection.bulkSlots, func(idx int) *wrpc.Result[types.MutationMetadata, types.DocumentError] {
+		id, doc := docs[idx].V0, docs[idx].V1
+		raw, ok := doc.GetRaw()
+		if !ok {
+			h.Logger.Error("Error getting raw document", "id", id)
+			return Err[types.MutationMetadata](*types.NewDocumentErrorNotJson())
+		}
+		result, err := connection.Collection.Insert(id, raw, InsertOptions(options))
+		if err != nil {
+			h.Logger.Error("Error inserting document", "id", id, "error", err)
+			return Err[types.MutationMetadata](bulkDocumentError(err, types.NewDocumentErrorInvalidValue()))
+		}
+		return Ok(MutationMetadata(result))
+	})
+}
+
+// UpsertMany implements document.Handler.
+func (h *Handler) UpsertMany(ctx context.Context, docs []*wrpc.Tuple2[string, *types.Document], options *document.DocumentUpsertOptions) (MutateManyResults, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	return bulk(ctx, len(docs), connection.bulkSlots, func(idx int) *wrpc.Result[types.MutationMetadata, types.DocumentError] {
+		id, doc := docs[idx].V0, docs[idx].V1
+		raw, ok := doc.GetRaw()
+		if !ok {
+			h.Logger.Error("Error getting raw document", "id", id)
+			return Err[types.MutationMetadata](*types.NewDocumentErrorNotJson())
+		}
+		result, err := connection.Collection.Upsert(id, raw, UpsertOptions(options))
+		if err != nil {
+			h.Logger.Error("Error upserting document", "id", id, "error", err)
+			return Err[types.MutationMetadata](bulkDocumentError(err, types.NewDocumentErrorInvalidValue()))
+		}
+		return Ok(MutationMetadata(result))
+	})
+}
+
+// RemoveMany implements document.Handler.
+func (h *Handler) RemoveMany(ctx context.Context, ids []string, options *document.DocumentRemoveOptions) (MutateManyResults, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	return bulk(ctx, len(ids), connection.bulkSlots, func(idx int) *wrpc.Result[types.MutationMetadata, types.DocumentError] {
+		result, err := connection.Collection.Remove(ids[idx], RemoveOptions(options))
+		if err != nil {
+			h.Logger.Error("Error removing document", "id", ids[idx], "error", err)
+			return Err[types.MutationMetadata](bulkDocumentError(err, types.NewDocumentErrorNotFound()))
+		}
+		return Ok(MutationMetadata(result))
+	})
+}
+
+// TouchMany implements document.Handler.
+func (h *Handler) TouchMany(ctx context.Context, ids []string, options *document.DocumentTouchOptions) (MutateManyResults, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	var expiry time.Duration
+	if options != nil {
+		expiry = time.Duration(options.ExpiresIn)
+	}
+	return bulk(ctx, len(ids), connection.bulkSlots, func(idx int) *wrpc.Result[types.MutationMetadata, types.DocumentError] {
+		result, err := connection.Collection.Touch(ids[idx], expiry, TouchOptions(options))
+		if err != nil {
+			h.Logger.Error("Error touching document", "id", ids[idx], "error", err)
+			return Err[types.MutationMetadata](bulkDocumentError(err, types.NewDocumentErrorNotFound()))
+		}
+		return Ok(MutationMetadata(result))
+	})
+}
+
+// bulk runs an operation for each of count documents, with at most one per slot running concurrently,
+// returning the results in the order of the documents. Once ctx is done no more operations are started,
+// and the error of ctx is returned after the operations already running complete
+func bulk[T any](ctx context.Context, count int, slots chan struct{}, op func(idx int) T) ([]T, error) {
+	results := make([]T, count)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	for idx := 0; idx < count; idx++ {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		// A slot may have been freed as ctx was done
+		if err := ctx.Err(); err != nil {
+			<-slots
+			return nil, err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			results[idx] = op(idx)
+		}()
+	}
+	return results, nil
+}
+
+// bulkDocumentError maps the error of a single document of a bulk operation,
+// using the fallback for errors that are not specific to the document
+func bulkDocumentError(err error, fallback *types.DocumentError) types.DocumentError {
+	if docErr := DocumentError(err); docErr != nil {
+		return *docErr
+	}
+	return *fallback
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulk(t *testing.T) {
+	var running, maxRunning atomic.Int32
+	op := func(idx int) int {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return idx * 2
+	}
+
+	// Bulk operations of the same link share its slots
+	slots := make(chan struct{}, 3)
+	var wg sync.WaitGroup
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, err := bulk(context.Background(), 20, slots, op)
+			if err != nil {
+				t.Errorf("expected no error, got %v", err)
+				return
+			}
+			if len(results) != 20 {
+				t.Errorf("expected 20 results, got %d", len(results))
+				return
+			}
+			for idx, result := range results {
+				if result != idx*2 {
+					t.Errorf("expected result %d at index %d, got %d", idx*2, idx, result)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if maxRunning.Load() > 3 {
+		t.Errorf("expected at most 3 concurrent operations, got %d", maxRunning.Load())
+	}
+}
+
+func TestBulkCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var started atomic.Int32
+	_, err := bulk(ctx, 20, make(chan struct{}, 1), func(idx int) int {
+		started.Add(1)
+		cancel()
+		return idx
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the bulk operation to be cancelled, got %v", err)
+	}
+	if started.Load() != 1 {
+		t.Errorf("expected no operations to start once cancelled, got %d", started.Load())
+	}
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+const (
+	// Delays between attempts to restart a failed feed or to redeliver a change
+	changeFeedMinBackoff = time.Second
+	changeFeedMaxBackoff = time.Minute
+	// How often the positions of delivered changes are checkpointed
+	changeFeedCheckpointInterval = 5 * time.Second
+	// How long a component may take to handle a single change
+	changeDeliveryTimeout = 30 * time.Second
+	// How many times a change is delivered before it is dropped, so that the following changes are delivered
+	changeDeliveryMaxAttempts = 5
+	// How many changes of a partition may wait to be delivered before the feed waits for them to be delivered
+	changeQueueSize = 1024
+)
+
+// Documents used to checkpoint change feeds are stored under this prefix, in the collection configured
+// by the `checkpointCollection` link config or else the watched collection, where they are never delivered to components
+const changeFeedCheckpointPrefix = "_changefeed::"
+
+// Kind of change made to a document
+type changeKind uint8
+
+const (
+	changeMutation changeKind = iota
+	changeDeletion
+	changeExpiration
+)
+
+// Position of a change in the history of a partition (vbucket)
+type feedPosition struct {
+	PartitionUUID uint64 `json:"partitionUuid"`
+	Seq           uint64 `json:"seq"`
+}
+
+// Positions of the last delivered change of each partition, from which a feed resumes
+type feedCheckpoint map[uint16]feedPosition
+
+// A change made to a document of the watched collection
+type documentChange struct {
+	kind      changeKind
+	id        string
+	cas       uint64
+	partition uint16
+	position  feedPosition
+	// Contents of the document, for mutations only
+	value []byte
+}
+
+// A stream of the changes made to the documents of a collection
+type changeFeed interface {
+	// run passes changes to handle, starting after the checkpointed position of each partition
+	// (or from the start of its history), until the context is cancelled or the feed fails.
+	// Changes of a partition are passed in order. handle is called by the goroutines receiving the changes,
+	// which may receive those of several partitions, so it should queue changes rather than deliver them.
+	run(ctx context.Context, from feedCheckpoint, handle func(context.Context, documentChange)) error
+}
+
+// Storage for the checkpoint of a change subscription
+type checkpointStore interface {
+	// load returns the last saved checkpoint, which is empty if none was saved
+	load() (feedCheckpoint, error)
+	save(feedCheckpoint) error
+	// stores reports whether a document of the watched collection stores checkpoints
+	stores(id string) bool
+}
+
+// Delivers a change to the subscribed component
+type changeDeliverer func(ctx context.Context, change documentChange) error
+
+// Delivers the changes of a collection to a component linked from the provider
+//
+// Changes are delivered at least once: a change is redelivered until the component handles it or
+// changeDeliveryMaxAttempts is reached, and changes delivered after the last checkpoint are delivered again
+// when the subscription restarts. Each partition is delivered by its own worker, so a change that is being
+// redelivered only holds up the changes of its partition.
+type changeSubscription struct {
+	target   string
+	linkName string
+
+	feed        changeFeed
+	checkpoints checkpointStore
+	deliver     changeDeliverer
+	logger      *slog.Logger
+
+	// Guards the delivered positions
+	mu        sync.Mutex
+	delivered feedCheckpoint
+	dirty     bool
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func newChangeSubscription(target, linkName string, feed changeFeed, checkpoints checkpointStore, deliver changeDeliverer, logger *slog.Logger) *changeSubscription {
+	return &changeSubscription{
+		target:      target,
+		linkName:    linkName,
+		feed:        feed,
+		checkpoints: checkpoints,
+		deliver:     deliver,
+		logger:      logger.With("target", target, "link", linkName),
+	}
+}
+
+// start delivers changes in the background until the subscription is stopped
+func (s *changeSubscription) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		s.run(ctx)
+	}()
+}
+
+// stop ends the delivery of changes, checkpointing the changes that were delivered
+func (s *changeSubscription) stop() {
+	s.cancel()
+	<-s.done
+	s.checkpoint()
+}
+
+func (s *changeSubscription) run(ctx context.Context) {
+	backoff := newBackoff(changeFeedMinBackoff, changeFeedMaxBackoff)
+
+	// The checkpoint must be known before changes are delivered, so the feed resumes where it left off
+	for s.delivered == nil {
+		checkpoint, err := s.checkpoints.load()
+		if err == nil {
+			s.mu.Lock()
+			s.delivered = checkpoint
+			s.mu.Unlock()
+			break
+		}
+		s.logger.Error("Error loading change feed checkpoint", "error", err)
+		if !backoff.wait(ctx) {
+			return
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(changeFeedCheckpointInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.checkpoint()
+			}
+		}
+	}()
+
+	backoff.reset()
+	for {
+		// Changes still queued when the feed stops were not delivered, and are streamed again when it restarts
+		runCtx, cancel := context.WithCancel(ctx)
+		queues := newPartitionQueues(runCtx, s.handle)
+		err := s.feed.run(runCtx, s.resumeFrom(), queues.push)
+		cancel()
+		queues.wait()
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Error("Change feed failed, restarting", "error", err)
+		if !backoff.wait(ctx) {
+			return
+		}
+	}
+}
+
+// handle delivers a change, retrying until it is handled, it failed changeDeliveryMaxAttempts times,
+// or the subscription is stopped
+func (s *changeSubscription) handle(ctx context.Context, change documentChange) {
+	// Recording the changes of checkpoints saved in the watched collection would keep checkpointing forever
+	if s.checkpoints.stores(change.id) {
+		return
+	}
+
+	backoff := newBackoff(changeFeedMinBackoff, changeFeedMaxBackoff)
+	for attempt := 1; ; attempt++ {
+		deliverCtx, cancel := context.WithTimeout(ctx, changeDeliveryTimeout)
+		err := s.deliver(deliverCtx, change)
+		cancel()
+		if err == nil {
+			break
+		}
+		if attempt == changeDeliveryMaxAttempts {
+			s.logger.Error("Dropping document change that could not be delivered",
+				"id", change.id, "partition", change.partition, "seq", change.position.Seq, "attempts", attempt, "error", err)
+			break
+		}
+		s.logger.Warn("Error delivering document change", "id", change.id, "attempt", attempt, "error", err)
+		// The change was not handled, so it must not be checkpointed
+		if !backoff.wait(ctx) {
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delivered[change.partition] = change.position
+	s.dirty = true
+}
+
+// resumeFrom returns a copy of the positions of the delivered changes
+func (s *changeSubscription) resumeFrom() feedCheckpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	from := make(feedCheckpoint, len(s.delivered))
+	for partition, position := range s.delivered {
+		from[partition] = position
+	}
+	return from
+}
+
+// checkpoint saves the positions of the delivered changes, if any were delivered since the last checkpoint
+func (s *changeSubscription) checkpoint() {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return
+	}
+	s.dirty = false
+	s.mu.Unlock()
+
+	if err := s.checkpoints.save(s.resumeFrom()); err != nil {
+		s.logger.Error("Error saving change feed checkpoint", "error", err)
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+	}
+}
+
+// Checkpoints stored as a document of the checkpoint collection of a link, which is its watched collection by default
+type collectionCheckpoints struct {
+	link *linkConnection
+	id   string
+}
+
+func newCollectionCheckpoints(link *linkConnection, target string) *collectionCheckpoints {
+	return &collectionCheckpoints{
+		link: link,
+		id:   changeFeedCheckpointPrefix + link.linkName + "::" + target,
+	}
+}
+
+// collection returns the collection checkpoints are stored in, once the link is connected
+func (c *collectionCheckpoints) collection() (*gocb.Collection, error) {
+	connection, err := c.link.await(context.Background(), c.link.connectionArgs.ReadyTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if keyspace := c.link.connectionArgs.CheckpointCollection; keyspace != nil {
+		return connection.Collection.Bucket().Scope(keyspace.ScopeName).Collection(keyspace.CollectionName), nil
+	}
+	return connection.Collection, nil
+}
+
+// stores implements checkpointStore, skipping the checkpoints of every subscription to the watched collection
+func (c *collectionCheckpoints) stores(id string) bool {
+	return c.link.connectionArgs.CheckpointCollection == nil && strings.HasPrefix(id, changeFeedCheckpointPrefix)
+}
+
+func (c *collectionCheckpoints) load() (feedCheckpoint, error) {
+	collection, err := c.collection()
+	if err != nil {
+		return nil, err
+	}
+	result, err := collection.Get(c.id, nil)
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return feedCheckpoint{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var checkpoint feedCheckpoint
+	if err := result.Content(&checkpoint); err != nil {
+		return nil, err
+	}
+	if checkpoint == nil {
+		checkpoint = feedCheckpoint{}
+	}
+	return checkpoint, nil
+}
+
+func (c *collectionCheckpoints) save(checkpoint feedCheckpoint) error {
+	collection, err := c.collection()
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	_, err = collection.Upsert(c.id, json.RawMessage(value), nil)
+	return err
+}
+
+// Changes of each partition waiting to be delivered by the worker of the partition
+type partitionQueues struct {
+	ctx     context.Context
+	deliver func(context.Context, documentChange)
+
+	mu      sync.Mutex
+	queues  map[uint16]chan documentChange
+	workers sync.WaitGroup
+}
+
+func newPartitionQueues(ctx context.Context, deliver func(context.Context, documentChange)) *partitionQueues {
+	return &partitionQueues{ctx: ctx, deliver: deliver, queues: make(map[uint16]chan documentChange)}
+}
+
+// push queues a change for delivery, waiting while the queue of its partition is full
+func (q *partitionQueues) push(_ context.Context, change documentChange) {
+	select {
+	case q.queue(change.partition) <- change:
+	case <-q.ctx.Done():
+	}
+}
+
+// queue returns the queue of a partition, starting its worker the first time a change of the partition is queued
+func (q *partitionQueues) queue(partition uint16) chan documentChange {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	queue, exists := q.queues[partition]
+	if exists {
+		return queue
+	}
+	queue = make(chan documentChange, changeQueueSize)
+	q.queues[partition] = queue
+	q.workers.Add(1)
+	go func() {
+		defer q.workers.Done()
+		for {
+			select {
+			case <-q.ctx.Done():
+				return
+			case change := <-queue:
+				q.deliver(q.ctx, change)
+			}
+		}
+	}()
+	return queue
+}
+
+// wait returns once the workers stopped, after the context of the queues is cancelled
+func (q *partitionQueues) wait() {
+	q.workers.Wait()
+}
+
+// A change subscription, along with the link it watches and checkpoints on
+type linkedChangeSubscription struct {
+	subscription *changeSubscription
+	link         *linkConnection
+}
+
+// stop ends the subscription, then closes its link once the delivered changes were checkpointed
+func (l *linkedChangeSubscription) stop() error {
+	l.subscription.stop()
+	return l.link.stop()
+}
+
+// Registry of the change subscriptions of the links from this provider
+type subscriptionRegistry struct {
+	mu sync.Mutex
+	// target -> linkName -> change subscription
+	subscriptions map[string]map[string]*linkedChangeSubscription
+}
+
+func newSubscriptionRegistry() *subscriptionRegistry {
+	return &subscriptionRegistry{subscriptions: make(map[string]map[string]*linkedChangeSubscription)}
+}
+
+// put registers a subscription, returning the subscription it replaced if there was one
+func (r *subscriptionRegistry) put(target, linkName string, subscription *linkedChangeSubscription) (*linkedChangeSubscription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subscriptions[target] == nil {
+		r.subscriptions[target] = make(map[string]*linkedChangeSubscription)
+	}
+	previous, exists := r.subscriptions[target][linkName]
+	r.subscriptions[target][linkName] = subscription
+	return previous, exists
+}
+
+// remove unregisters a subscription
+func (r *subscriptionRegistry) remove(target, linkName string) (*linkedChangeSubscription, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	subscription, exists := r.subscriptions[target][linkName]
+	if !exists {
+		return nil, false
+	}
+	delete(r.subscriptions[target], linkName)
+	if len(r.subscriptions[target]) == 0 {
+		delete(r.subscriptions, target)
+	}
+	return subscription, true
+}
+
+// removeAll unregisters all subscriptions
+func (r *subscriptionRegistry) removeAll() []*linkedChangeSubscription {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var subscriptions []*linkedChangeSubscription
+	for _, linked := range r.subscriptions {
+		for _, subscription := range linked {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	clear(r.subscriptions)
+	return subscriptions
+}
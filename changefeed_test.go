@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A change feed replaying changes kept in memory
+type memoryFeed struct {
+	mu      sync.Mutex
+	changes []documentChange
+}
+
+func (f *memoryFeed) append(partition uint16, id string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var seq uint64
+	for _, change := range f.changes {
+		if change.partition == partition {
+			seq = change.position.Seq
+		}
+	}
+	f.changes = append(f.changes, documentChange{
+		kind:      changeMutation,
+		id:        id,
+		value:     []byte(`{}`),
+		partition: partition,
+		position:  feedPosition{PartitionUUID: 1, Seq: seq + 1},
+	})
+}
+
+func (f *memoryFeed) run(ctx context.Context, from feedCheckpoint, handle func(context.Context, documentChange)) error {
+	f.mu.Lock()
+	changes := append([]documentChange(nil), f.changes...)
+	f.mu.Unlock()
+	for _, change := range changes {
+		if change.position.Seq > from[change.partition].Seq {
+			handle(ctx, change)
+		}
+	}
+	<-ctx.Done()
+	return nil
+}
+
+type memoryCheckpoints struct {
+	mu         sync.Mutex
+	checkpoint feedCheckpoint
+}
+
+func (c *memoryCheckpoints) load() (feedCheckpoint, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	checkpoint := feedCheckpoint{}
+	for partition, position := range c.checkpoint {
+		checkpoint[partition] = position
+	}
+	return checkpoint, nil
+}
+
+func (c *memoryCheckpoints) save(checkpoint feedCheckpoint) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkpoint = checkpoint
+	return nil
+}
+
+func (c *memoryCheckpoints) stores(id string) bool {
+	return strings.HasPrefix(id, changeFeedCheckpointPrefix)
+}
+
+// receive collects the IDs of the delivered changes, failing the first delivery of the given IDs
+func receive(failOnce ...string) (changeDeliverer, <-chan string) {
+	delivered := make(chan string, 16)
+	failed := make(map[string]bool)
+	for _, id := range failOnce {
+		failed[id] = false
+	}
+	return func(_ context.Context, change documentChange) error {
+		if alreadyFailed, ok := failed[change.id]; ok && !alreadyFailed {
+			failed[change.id] = true
+			return errors.New("component unavailable")
+		}
+		delivered <- change.id
+		return nil
+	}, delivered
+}
+
+func expectDelivered(t *testing.T, delivered <-chan string, expected ...string) {
+	t.Helper()
+	for _, id := range expected {
+		select {
+		case actual := <-delivered:
+			if actual != id {
+				t.Fatalf("expected change to %s to be delivered, got %s", id, actual)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected change to %s to be delivered", id)
+		}
+	}
+	select {
+	case actual := <-delivered:
+		t.Fatalf("expected no more changes to be delivered, got %s", actual)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// expectDeliveredUnordered expects changes of different partitions, which may be delivered in any order
+func expectDeliveredUnordered(t *testing.T, delivered <-chan string, expected ...string) {
+	t.Helper()
+	var actual []string
+	for range expected {
+		select {
+		case id := <-delivered:
+			actual = append(actual, id)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("expected changes to %v to be delivered, got %v", expected, actual)
+		}
+	}
+	expected = slices.Clone(expected)
+	slices.Sort(expected)
+	slices.Sort(actual)
+	if !slices.Equal(actual, expected) {
+		t.Fatalf("expected changes to %v to be delivered, got %v", expected, actual)
+	}
+	expectDelivered(t, delivered)
+}
+
+func TestChangeSubscriptionResumesFromCheckpoint(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	feed := &memoryFeed{}
+	checkpoints := &memoryCheckpoints{}
+	feed.append(0, "a")
+	feed.append(1, "b")
+	feed.append(0, changeFeedCheckpointPrefix+"other")
+	feed.append(0, "c")
+
+	deliver, delivered := receive()
+	subscription := newChangeSubscription("component", "default", feed, checkpoints, deliver, logger)
+	subscription.start()
+	expectDeliveredUnordered(t, delivered, "a", "b", "c")
+	subscription.stop()
+
+	if checkpoints.checkpoint[0].Seq != 3 || checkpoints.checkpoint[1].Seq != 1 {
+		t.Fatalf("expected the delivered positions to be checkpointed, got %v", checkpoints.checkpoint)
+	}
+
+	feed.append(1, "d")
+	deliver, delivered = receive()
+	subscription = newChangeSubscription("component", "default", feed, checkpoints, deliver, logger)
+	subscription.start()
+	expectDelivered(t, delivered, "d")
+	subscription.stop()
+}
+
+func TestChangeSubscriptionRedeliversFailedChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	feed := &memoryFeed{}
+	checkpoints := &memoryCheckpoints{}
+	feed.append(0, "a")
+	feed.append(0, "b")
+
+	deliver, delivered := receive("a")
+	subscription := newChangeSubscription("component", "default", feed, checkpoints, deliver, logger)
+	subscription.start()
+	expectDelivered(t, delivered, "a", "b")
+	subscription.stop()
+}
+
+func TestChangeSubscriptionSkipsCheckpointChanges(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	deliver, delivered := receive()
+	subscription := newChangeSubscription("component", "default", &memoryFeed{}, &memoryCheckpoints{}, deliver, logger)
+	subscription.delivered = feedCheckpoint{}
+
+	subscription.handle(context.Background(), documentChange{
+		kind:     changeMutation,
+		id:       changeFeedCheckpointPrefix + "default::component",
+		position: feedPosition{PartitionUUID: 1, Seq: 1},
+	})
+	expectDelivered(t, delivered)
+	if subscription.dirty || len(subscription.delivered) != 0 {
+		t.Fatalf("expected checkpoint changes not to be checkpointed, got %v", subscription.delivered)
+	}
+}
+
+func TestChangeSubscriptionDeliversPartitionsIndependently(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	feed := &memoryFeed{}
+	feed.append(0, "a")
+	feed.append(1, "b")
+
+	// The change of partition 0 is held until the change of partition 1 was delivered
+	release := make(chan struct{})
+	delivered := make(chan string, 2)
+	deliver := func(ctx context.Context, change documentChange) error {
+		if change.id == "a" {
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		delivered <- change.id
+		return nil
+	}
+	subscription := newChangeSubscription("component", "default", feed, &memoryCheckpoints{}, deliver, logger)
+	subscription.start()
+	expectDelivered(t, delivered, "b")
+	close(release)
+	expectDelivered(t, delivered, "a")
+	subscription.stop()
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	gocbt "github.com/couchbase/gocb-opentelemetry"
+	"github.com/couchbase/gocb/v2"
+	"go.opentelemetry.io/otel"
+)
+
+// How long a parallel connection may take to authenticate with rotated credentials
+const credentialsVerifyTimeout = 10 * time.Second
+
+// The cluster and user a link connects as, links with the same identity share a cluster
+type clusterIdentity struct {
+	ConnectionString string
+	Username         string
+}
+
+func newClusterIdentity(connectionArgs CouchbaseConnectionArgs) clusterIdentity {
+	return clusterIdentity{
+		ConnectionString: connectionArgs.ConnectionString,
+		Username:         connectionArgs.Username,
+	}
+}
+
+// A password authenticator whose password can be rotated while the cluster is connected.
+// The cluster asks for credentials whenever it opens a connection or sends an HTTP request,
+// so connections that are already authenticated keep being used after a rotation
+type rotatingAuthenticator struct {
+	gocb.PasswordAuthenticator
+	mu sync.RWMutex
+}
+
+func newRotatingAuthenticator(username, password string) *rotatingAuthenticator {
+	return &rotatingAuthenticator{
+		PasswordAuthenticator: gocb.PasswordAuthenticator{Username: username, Password: password},
+	}
+}
+
+// Credentials implements gocb.Authenticator.
+func (a *rotatingAuthenticator) Credentials(req gocb.AuthCredsRequest) ([]gocb.UserPassPair, error) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.PasswordAuthenticator.Credentials(req)
+}
+
+func (a *rotatingAuthenticator) password() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return a.PasswordAuthenticator.Password
+}
+
+func (a *rotatingAuthenticator) rotate(password string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.PasswordAuthenticator.Password = password
+}
+
+// A cluster connection shared by the links with the same identity
+type sharedCluster struct {
+	cluster *gocb.Cluster
+	auth    *rotatingAuthenticator
+	// Buckets opened on the cluster, keyed by name
+	buckets map[string]*gocb.Bucket
+	// How many leases on the cluster have not been released
+	leases int
+}
+
+// Registry of the cluster connections in use by links
+type clusterPool struct {
+	mu       sync.Mutex
+	clusters map[clusterIdentity]*sharedCluster
+	// Connects to a cluster, and closes a cluster once its last lease is released
+	connect func(identity clusterIdentity, auth gocb.Authenticator) (*gocb.Cluster, error)
+	close   func(cluster *gocb.Cluster) error
+	// Checks that rotated credentials are accepted by a cluster, before a connected cluster uses them
+	verify func(identity clusterIdentity, password string) error
+}
+
+// A reference to a shared cluster held by a link, until it is released
+type clusterLease struct {
+	pool     *clusterPool
+	identity clusterIdentity
+	shared   *sharedCluster
+	cluster  *gocb.Cluster
+	auth     *rotatingAuthenticator
+	release  func() error
+	// Whether acquiring the lease rotated the credentials of the cluster
+	rotated bool
+}
+
+func newClusterPool() *clusterPool {
+	connect := func(identity clusterIdentity, auth gocb.Authenticator) (*gocb.Cluster, error) {
+		return gocb.Connect(identity.ConnectionString, gocb.ClusterOptions{
+			Authenticator: auth,
+			Tracer:        gocbt.NewOpenTelemetryRequestTracer(otel.GetTracerProvider()),
+		})
+	}
+	return &clusterPool{
+		clusters: make(map[clusterIdentity]*sharedCluster),
+		connect:  connect,
+		close: func(cluster *gocb.Cluster) error {
+			return cluster.Close(nil)
+		},
+		verify: func(identity clusterIdentity, password string) error {
+			cluster, err := gocb.Connect(identity.ConnectionString, gocb.ClusterOptions{
+				Authenticator: gocb.PasswordAuthenticator{Username: identity.Username, Password: password},
+			})
+			if err != nil {
+				return err
+			}
+			defer cluster.Close(nil)
+			return cluster.WaitUntilReady(credentialsVerifyTimeout, nil)
+		},
+	}
+}
+
+// acquire leases the cluster of an identity, connecting to it if no other link uses it.
+// A password that differs from the one the cluster was connected with rotates the credentials
+// of the cluster, once a parallel connection authenticated with it
+func (p *clusterPool) acquire(identity clusterIdentity, password string) (*clusterLease, error) {
+	for {
+		p.mu.Lock()
+		shared, exists := p.clusters[identity]
+		if !exists {
+			// Other links keep using the pool while the cluster is connected to
+			p.mu.Unlock()
+			auth := newRotatingAuthenticator(identity.Username, password)
+			cluster, err := p.connect(identity, auth)
+			if err != nil {
+				return nil, err
+			}
+			p.mu.Lock()
+			if _, exists := p.clusters[identity]; exists {
+				// Another link connected to the cluster first, its connection is shared instead
+				p.mu.Unlock()
+				_ = p.close(cluster)
+				continue
+			}
+			shared = &sharedCluster{cluster: cluster, auth: auth, buckets: make(map[string]*gocb.Bucket)}
+			p.clusters[identity] = shared
+			return p.lease(identity, shared, false), nil
+		}
+
+		rotated := false
+		if shared.auth.password() != password {
+			// Other links keep using the cluster while the new password is verified
+			p.mu.Unlock()
+			if err := p.verify(identity, password); err != nil {
+				return nil, err
+			}
+			p.mu.Lock()
+			if p.clusters[identity] != shared {
+				// The cluster was closed or replaced while the password was verified
+				p.mu.Unlock()
+				continue
+			}
+			shared.auth.rotate(password)
+			rotated = true
+		}
+		return p.lease(identity, shared, rotated), nil
+	}
+}
+
+// lease takes a lease on a shared cluster, with the pool locked
+func (p *clusterPool) lease(identity clusterIdentity, shared *sharedCluster, rotated bool) *clusterLease {
+	defer p.mu.Unlock()
+	shared.leases++
+	lease := &clusterLease{pool: p, identity: identity, shared: shared, cluster: shared.cluster, auth: shared.auth, rotated: rotated}
+	lease.release = sync.OnceValue(func() error {
+		return p.release(identity, shared)
+	})
+	return lease
+}
+
+// release gives back a lease on a shared cluster, closing the cluster after the last one.
+// Leases on a cluster that was already closed, or replaced by another connection, are ignored
+func (p *clusterPool) release(identity clusterIdentity, shared *sharedCluster) error {
+	p.mu.Lock()
+	if p.clusters[identity] != shared {
+		p.mu.Unlock()
+		return nil
+	}
+	shared.leases--
+	if shared.leases > 0 {
+		p.mu.Unlock()
+		return nil
+	}
+	delete(p.clusters, identity)
+	p.mu.Unlock()
+	return p.close(shared.cluster)
+}
+
+// closeAll closes every cluster, whether or not its leases were released
+func (p *clusterPool) closeAll() error {
+	p.mu.Lock()
+	clusters := p.clusters
+	p.clusters = make(map[clusterIdentity]*sharedCluster)
+	p.mu.Unlock()
+
+	var errs []error
+	for _, shared := range clusters {
+		if err := p.close(shared.cluster); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// bucket returns a handle on a bucket of the leased cluster, opening the bucket if no other link uses it
+func (l *clusterLease) bucket(name string) *gocb.Bucket {
+	l.pool.mu.Lock()
+	defer l.pool.mu.Unlock()
+	if l.pool.clusters[l.identity] != l.shared {
+		// The cluster was closed, which fails any operation on the bucket
+		return l.cluster.Bucket(name)
+	}
+	bucket, exists := l.shared.buckets[name]
+	if !exists {
+		bucket = l.cluster.Bucket(name)
+		l.shared.buckets[name] = bucket
+	}
+	return bucket
+}
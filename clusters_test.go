@@ -0,0 +1,101 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+// A cluster pool that counts the clusters it connects to and closes, without connecting
+func countingClusterPool() (pool *clusterPool, connected *int, closed *int) {
+	connected, closed = new(int), new(int)
+	pool = newClusterPool()
+	pool.connect = func(clusterIdentity, gocb.Authenticator) (*gocb.Cluster, error) {
+		*connected++
+		return &gocb.Cluster{}, nil
+	}
+	pool.close = func(*gocb.Cluster) error {
+		*closed++
+		return nil
+	}
+	return pool, connected, closed
+}
+
+func TestClusterPoolSharesClusters(t *testing.T) {
+	pool, connected, closed := countingClusterPool()
+	identity := clusterIdentity{ConnectionString: "couchbase://localhost", Username: "app"}
+
+	first, _ := pool.acquire(identity, "secret")
+	second, _ := pool.acquire(identity, "secret")
+	other, _ := pool.acquire(clusterIdentity{ConnectionString: "couchbase://localhost", Username: "admin"}, "secret")
+	if *connected != 2 || first.cluster != second.cluster || first.cluster == other.cluster {
+		t.Fatalf("expected links with the same identity to share a cluster, connected %d clusters", *connected)
+	}
+
+	// Releasing a lease more than once must not release the leases of other links
+	_ = first.release()
+	_ = first.release()
+	if *closed != 0 {
+		t.Fatalf("expected cluster to stay open while leased, closed %d clusters", *closed)
+	}
+	_ = second.release()
+	if *closed != 1 {
+		t.Fatalf("expected cluster to be closed once its last lease was released, closed %d clusters", *closed)
+	}
+
+	third, _ := pool.acquire(identity, "secret")
+	if *connected != 3 || third.cluster == first.cluster {
+		t.Fatalf("expected a closed cluster to be connected again, connected %d clusters", *connected)
+	}
+
+	_ = pool.closeAll()
+	if *closed != 3 {
+		t.Fatalf("expected all clusters to be closed, closed %d clusters", *closed)
+	}
+}
+
+func TestClusterPoolRotatesCredentials(t *testing.T) {
+	pool, connected, _ := countingClusterPool()
+	accepted := "rotated"
+	pool.verify = func(_ clusterIdentity, password string) error {
+		if password != accepted {
+			return gocb.ErrAuthenticationFailure
+		}
+		return nil
+	}
+	identity := clusterIdentity{ConnectionString: "couchbase://localhost", Username: "app"}
+
+	first, _ := pool.acquire(identity, "secret")
+	if _, err := pool.acquire(identity, "wrong"); !errors.Is(err, gocb.ErrAuthenticationFailure) {
+		t.Fatalf("expected credentials that are not accepted to not be rotated, got %v", err)
+	}
+	second, err := pool.acquire(identity, "rotated")
+	if err != nil || !second.rotated || second.cluster != first.cluster || *connected != 1 {
+		t.Fatalf("expected the credentials of the connected cluster to be rotated, got %v", err)
+	}
+
+	credentials, _ := pool.clusters[identity].auth.Credentials(gocb.AuthCredsRequest{})
+	if len(credentials) != 1 || credentials[0].Password != "rotated" {
+		t.Fatalf("expected the cluster to authenticate with the rotated password, got %v", credentials)
+	}
+}
+
+func TestClusterPoolIgnoresLeasesOfClosedClusters(t *testing.T) {
+	pool, _, closed := countingClusterPool()
+	identity := clusterIdentity{ConnectionString: "couchbase://localhost", Username: "app"}
+
+	stale, _ := pool.acquire(identity, "secret")
+	_ = pool.closeAll()
+	current, _ := pool.acquire(identity, "secret")
+
+	// The lease on the closed cluster must not release the lease on the cluster connected since
+	_ = stale.release()
+	if *closed != 1 || pool.clusters[identity] == nil {
+		t.Fatalf("expected the connected cluster to stay open, closed %d clusters", *closed)
+	}
+	_ = current.release()
+	if *closed != 2 {
+		t.Fatalf("expected the connected cluster to be closed once released, closed %d clusters", *closed)
+	}
+}
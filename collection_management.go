@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/collection_management"
+)
+
+// GetAllScopes implements collection_management.Handler.
+func (h *Handler) GetAllScopes(ctx context.Context, options *collection_management.CollectionManagementOptions) (*wrpc.Result[[]*collection_management.ScopeSpec, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[[]*collection_management.ScopeSpec](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	scopes, err := manager.GetAllScopes(GetAllScopesOptions(options))
+	if err != nil {
+		h.Logger.Error("Error listing scopes", "error", err)
+		return wrpc.Err[[]*collection_management.ScopeSpec](*CollectionManagementError(err, "", "")), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](ScopeSpecs(scopes)), nil
+}
+
+// CreateScope implements collection_management.Handler.
+func (h *Handler) CreateScope(ctx context.Context, scopeName string, options *collection_management.CollectionManagementOptions) (*wrpc.Result[struct{}, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	if err := manager.CreateScope(scopeName, CreateScopeOptions(options)); err != nil {
+		h.Logger.Error("Error creating scope", "scope", scopeName, "error", err)
+		return wrpc.Err[struct{}](*CollectionManagementError(err, scopeName, "")), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](struct{}{}), nil
+}
+
+// DropScope implements collection_management.Handler.
+func (h *Handler) DropScope(ctx context.Context, scopeName string, options *collection_management.CollectionManagementOptions) (*wrpc.Result[struct{}, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	if err := manager.DropScope(scopeName, DropScopeOptions(options)); err != nil {
+		h.Logger.Error("Error dropping scope", "scope", scopeName, "error", err)
+		return wrpc.Err[struct{}](*CollectionManagementError(err, scopeName, "")), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](struct{}{}), nil
+}
+
+// CreateCollection implements collection_management.Handler.
+func (h *Handler) CreateCollection(ctx context.Context, scopeName string, collectionName string, settings *collection_management.CollectionSettings, options *collection_management.CollectionManagementOptions) (*wrpc.Result[struct{}, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	if err := manager.CreateCollection(scopeName, collectionName, CreateCollectionSettings(settings), CreateCollectionOptions(options)); err != nil {
+		h.Logger.Error("Error creating collection", "scope", scopeName, "collection", collectionName, "error", err)
+		return wrpc.Err[struct{}](*CollectionManagementError(err, scopeName, collectionName)), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](struct{}{}), nil
+}
+
+// UpdateCollection implements collection_management.Handler.
+func (h *Handler) UpdateCollection(ctx context.Context, scopeName string, collectionName string, settings *collection_management.CollectionSettings, options *collection_management.CollectionManagementOptions) (*wrpc.Result[struct{}, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	if err := manager.UpdateCollection(scopeName, collectionName, UpdateCollectionSettings(settings), UpdateCollectionOptions(options)); err != nil {
+		h.Logger.Error("Error updating collection", "scope", scopeName, "collection", collectionName, "error", err)
+		return wrpc.Err[struct{}](*CollectionManagementError(err, scopeName, collectionName)), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](struct{}{}), nil
+}
+
+// DropCollection implements collection_management.Handler.
+func (h *Handler) DropCollection(ctx context.Context, scopeName string, collectionName string, options *collection_management.CollectionManagementOptions) (*wrpc.Result[struct{}, collection_management.CollectionManagementError], error) {
+	manager, err := h.getCollectionManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, collection_management.NewCollectionManagementErrorNotAllowed())
+	}
+	if err := manager.DropCollection(scopeName, collectionName, DropCollectionOptions(options)); err != nil {
+		h.Logger.Error("Error dropping collection", "scope", scopeName, "collection", collectionName, "error", err)
+		return wrpc.Err[struct{}](*CollectionManagementError(err, scopeName, collectionName)), nil
+	}
+	return wrpc.Ok[collection_management.CollectionManagementError](struct{}{}), nil
+}
+
+// Helper function to get the collection manager of the bucket configured on the link the invocation was made on
+func (h *Handler) getCollectionManagerFromContext(ctx context.Context) (*gocb.CollectionManagerV2, error) {
+	connection, err := h.getManagementConnectionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return connection.Collection.Bucket().CollectionsV2(), nil
+}
+
+// CollectionManagementError maps errors of collection management operations to their collection management error
+func CollectionManagementError(err error, scopeName, collectionName string) *collection_management.CollectionManagementError {
+	switch {
+	case errors.Is(err, gocb.ErrScopeNotFound):
+		return collection_management.NewCollectionManagementErrorScopeNotFound(scopeName)
+	case errors.Is(err, gocb.ErrScopeExists):
+		return collection_management.NewCollectionManagementErrorScopeExists(scopeName)
+	case errors.Is(err, gocb.ErrCollectionNotFound):
+		return collection_management.NewCollectionManagementErrorCollectionNotFound(collectionName)
+	case errors.Is(err, gocb.ErrCollectionExists):
+		return collection_management.NewCollectionManagementErrorCollectionExists(collectionName)
+	case errors.Is(err, gocb.ErrInvalidArgument), errors.Is(err, gocb.ErrFeatureNotAvailable):
+		return collection_management.NewCollectionManagementErrorInvalidArgument(err.Error())
+	case errors.Is(err, gocb.ErrTimeout):
+		return collection_management.NewCollectionManagementErrorTimeout()
+	default:
+		return collection_management.NewCollectionManagementErrorUnexpected(err.Error())
+	}
+}
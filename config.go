@@ -3,6 +3,9 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.wasmcloud.dev/provider"
 )
@@ -14,8 +17,42 @@ type CouchbaseConnectionArgs struct {
 	ConnectionString string
 	ScopeName        string
 	CollectionName   string
+	// How long a SQL++ query cursor, KV scan cursor or key listing may remain unused before it is closed
+	CursorIdleTimeout time.Duration
+	// How long a transaction may remain unused before it is rolled back
+	TransactionIdleTimeout time.Duration
+	// How many documents of a bulk operation may be processed concurrently
+	BulkConcurrency int
+	// Whether the link may manage the scopes, collections and indexes of the bucket
+	AllowManagement bool
+	// Keyspaces of the bucket that keyvalue stores are opened on, keyed by alias
+	KeyvalueStores map[string]KeyvalueKeyspace
+	// Collection of the bucket that change feeds are checkpointed in, the watched collection if not set
+	CheckpointCollection *KeyvalueKeyspace
+	// Collection of the bucket that blobstore containers and objects are stored in, the link's collection if not set
+	BlobstoreCollection *KeyvalueKeyspace
+	// How long requests made while the link is connecting wait for it to be ready
+	ReadyTimeout time.Duration
 }
 
+// A collection of the bucket of a link, used as a keyvalue store
+type KeyvalueKeyspace struct {
+	ScopeName      string
+	CollectionName string
+}
+
+// Cursors left open by components are closed after this long by default
+const defaultCursorIdleTimeout = 5 * time.Minute
+
+// Transactions abandoned by components are rolled back after this long by default
+const defaultTransactionIdleTimeout = time.Minute
+
+// Documents of bulk operations are processed this many at a time by default
+const defaultBulkConcurrency = 16
+
+// Requests made while a link is connecting wait this long for it to be ready by default
+const defaultReadyTimeout = 5 * time.Second
+
 // Construct Couchbase connection args from config and secrets
 func validateCouchbaseConfig(config map[string]string, secrets map[string]provider.SecretValue) (CouchbaseConnectionArgs, error) {
 	connectionArgs := CouchbaseConnectionArgs{}
@@ -51,9 +88,109 @@ func validateCouchbaseConfig(config map[string]string, secrets map[string]provid
 		connectionArgs.CollectionName = collectionName
 	}
 
+	connectionArgs.CursorIdleTimeout = defaultCursorIdleTimeout
+	if idleTimeout, err := getConfigValue(config, secrets, "cursorIdleTimeout"); err == nil {
+		timeout, err := time.ParseDuration(idleTimeout)
+		if err != nil || timeout <= 0 {
+			return connectionArgs, fmt.Errorf("cursorIdleTimeout must be a positive duration, got '%s'", idleTimeout)
+		}
+		connectionArgs.CursorIdleTimeout = timeout
+	}
+
+	connectionArgs.TransactionIdleTimeout = defaultTransactionIdleTimeout
+	if idleTimeout, err := getConfigValue(config, secrets, "transactionIdleTimeout"); err == nil {
+		timeout, err := time.ParseDuration(idleTimeout)
+		if err != nil || timeout <= 0 {
+			return connectionArgs, fmt.Errorf("transactionIdleTimeout must be a positive duration, got '%s'", idleTimeout)
+		}
+		connectionArgs.TransactionIdleTimeout = timeout
+	}
+
+	// A zero timeout fails requests made while the link is connecting right away
+	connectionArgs.ReadyTimeout = defaultReadyTimeout
+	if readyTimeout, err := getConfigValue(config, secrets, "readyTimeout"); err == nil {
+		timeout, err := time.ParseDuration(readyTimeout)
+		if err != nil || timeout < 0 {
+			return connectionArgs, fmt.Errorf("readyTimeout must be a non-negative duration, got '%s'", readyTimeout)
+		}
+		connectionArgs.ReadyTimeout = timeout
+	}
+
+	connectionArgs.BulkConcurrency = defaultBulkConcurrency
+	if bulkConcurrency, err := getConfigValue(config, secrets, "bulkConcurrency"); err == nil {
+		concurrency, err := strconv.Atoi(bulkConcurrency)
+		if err != nil || concurrency <= 0 {
+			return connectionArgs, fmt.Errorf("bulkConcurrency must be a positive integer, got '%s'", bulkConcurrency)
+		}
+		connectionArgs.BulkConcurrency = concurrency
+	}
+
+	// Management operations are disabled unless explicitly allowed
+	if allowManagement, err := getConfigValue(config, secrets, "allowManagement"); err == nil {
+		allowed, err := strconv.ParseBool(allowManagement)
+		if err != nil {
+			return connectionArgs, fmt.Errorf("allowManagement must be a boolean, got '%s'", allowManagement)
+		}
+		connectionArgs.AllowManagement = allowed
+	}
+
+	// Keyvalue store aliases are optional, stores can also be opened by keyspace
+	if keyvalueStores, err := getConfigValue(config, secrets, "keyvalueStores"); err == nil {
+		stores, err := parseKeyvalueStores(keyvalueStores)
+		if err != nil {
+			return connectionArgs, fmt.Errorf("keyvalueStores must be a list of alias=scope.collection pairs: %w", err)
+		}
+		connectionArgs.KeyvalueStores = stores
+	}
+
+	// Change feeds are checkpointed in the watched collection unless a collection is set
+	if checkpointCollection, err := getConfigValue(config, secrets, "checkpointCollection"); err == nil {
+		keyspace, ok := parseKeyvalueKeyspace(checkpointCollection)
+		if !ok {
+			return connectionArgs, fmt.Errorf("checkpointCollection must be a scope.collection keyspace, got '%s'", checkpointCollection)
+		}
+		connectionArgs.CheckpointCollection = &keyspace
+	}
+
+	// Blobstore containers and objects are stored in the collection of the link unless a collection is set
+	if blobstoreCollection, err := getConfigValue(config, secrets, "blobstoreCollection"); err == nil {
+		keyspace, ok := parseKeyvalueKeyspace(blobstoreCollection)
+		if !ok {
+			return connectionArgs, fmt.Errorf("blobstoreCollection must be a scope.collection keyspace, got '%s'", blobstoreCollection)
+		}
+		connectionArgs.BlobstoreCollection = &keyspace
+	}
+
 	return connectionArgs, nil
 }
 
+// parseKeyvalueStores parses comma separated alias=scope.collection pairs
+func parseKeyvalueStores(value string) (map[string]KeyvalueKeyspace, error) {
+	stores := make(map[string]KeyvalueKeyspace)
+	for _, pair := range strings.Split(value, ",") {
+		alias, keyspace, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		alias = strings.TrimSpace(alias)
+		if !ok || alias == "" {
+			return nil, fmt.Errorf("invalid store '%s'", pair)
+		}
+		parsed, ok := parseKeyvalueKeyspace(strings.TrimSpace(keyspace))
+		if !ok {
+			return nil, fmt.Errorf("invalid keyspace for store '%s'", alias)
+		}
+		stores[alias] = parsed
+	}
+	return stores, nil
+}
+
+// parseKeyvalueKeyspace parses a scope.collection keyspace
+func parseKeyvalueKeyspace(keyspace string) (KeyvalueKeyspace, bool) {
+	scopeName, collectionName, ok := strings.Cut(keyspace, ".")
+	if !ok || scopeName == "" || collectionName == "" || strings.Contains(collectionName, ".") {
+		return KeyvalueKeyspace{}, false
+	}
+	return KeyvalueKeyspace{ScopeName: scopeName, CollectionName: collectionName}, true
+}
+
 // getConfigValue retrieves the value for a given key from either the secrets map or the config map.
 // It first checks the secrets map and returns the revealed secret if it's not empty.
 // If not found, it checks the config map and returns the value if it's not empty.
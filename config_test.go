@@ -48,3 +48,25 @@ func TestGetConfigValue(t *testing.T) {
 		}
 	}
 }
+
+func TestParseKeyvalueStores(t *testing.T) {
+	stores, err := parseKeyvalueStores("sessions=app.sessions, cache = app.cache")
+	if err != nil {
+		t.Fatalf("unexpected error parsing stores: %v", err)
+	}
+	expected := map[string]KeyvalueKeyspace{
+		"sessions": {ScopeName: "app", CollectionName: "sessions"},
+		"cache":    {ScopeName: "app", CollectionName: "cache"},
+	}
+	for alias, keyspace := range expected {
+		if stores[alias] != keyspace {
+			t.Errorf("expected store '%s' to be %v, got %v", alias, keyspace, stores[alias])
+		}
+	}
+
+	for _, invalid := range []string{"sessions", "=app.sessions", "sessions=app", "sessions=app.sessions.extra", "sessions=.sessions"} {
+		if _, err := parseKeyvalueStores(invalid); err == nil {
+			t.Errorf("expected error parsing stores '%s', got none", invalid)
+		}
+	}
+}
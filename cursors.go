@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/google/uuid"
+)
+
+// How often abandoned cursors are looked for
+const cursorReapInterval = 30 * time.Second
+
+// Most rows or documents read from a cursor at once, whatever a component asks for
+const cursorBatchLimit = 1000
+
+var (
+	errQueryCursorNotFound = errors.New("query handle does not exist")
+	errScanCursorNotFound  = errors.New("scan handle does not exist")
+)
+
+// A result stream opened by a linked component, read in batches through successive invocations
+type cursor interface {
+	owner() *cursorOwner
+	close() error
+}
+
+// Link and usage tracking shared by every kind of cursor
+type cursorOwner struct {
+	sourceId string
+	linkName string
+
+	// Serializes reads of the result stream, as gocb results are not safe for concurrent use
+	mu          sync.Mutex
+	lastUsed    time.Time
+	idleTimeout time.Duration
+	// Set once the cursor is closed, which may happen after an invocation got the cursor but before it read from it
+	closed bool
+}
+
+// An open SQL++ query whose rows are streamed to a component in batches
+type queryCursor struct {
+	cursorOwner
+	result *gocb.QueryResult
+	done   bool
+}
+
+// Registry of the cursors opened by linked components
+type cursors[C cursor] struct {
+	mu       sync.Mutex
+	cursors  map[string]C
+	notFound error
+	// Generates the handles of opened cursors, which are random UUIDs by default
+	newHandle func() string
+}
+
+type queryCursors = cursors[*queryCursor]
+
+func newQueryCursors() *queryCursors {
+	return &queryCursors{cursors: make(map[string]*queryCursor), notFound: errQueryCursorNotFound}
+}
+
+func newCursorOwner(sourceId, linkName string, idleTimeout time.Duration) cursorOwner {
+	return cursorOwner{
+		sourceId:    sourceId,
+		linkName:    linkName,
+		lastUsed:    time.Now(),
+		idleTimeout: idleTimeout,
+	}
+}
+
+func newQueryCursor(sourceId, linkName string, result *gocb.QueryResult, idleTimeout time.Duration) *queryCursor {
+	return &queryCursor{
+		cursorOwner: newCursorOwner(sourceId, linkName, idleTimeout),
+		result:      result,
+	}
+}
+
+// open registers a cursor, returning the handle identifying it
+func (c *cursors[C]) open(cursor C) string {
+	newHandle := c.newHandle
+	if newHandle == nil {
+		newHandle = uuid.NewString
+	}
+	handle := newHandle()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cursors[handle] = cursor
+	return handle
+}
+
+// get returns the cursor for a handle, only if it was opened on the given link
+func (c *cursors[C]) get(sourceId, linkName, handle string) (C, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cursor, ok := c.cursors[handle]
+	if !ok || !cursor.owner().openedOn(sourceId, linkName) {
+		var none C
+		return none, c.notFound
+	}
+	return cursor, nil
+}
+
+// close removes a cursor and closes its result stream
+func (c *cursors[C]) close(sourceId, linkName, handle string) error {
+	c.mu.Lock()
+	cursor, ok := c.cursors[handle]
+	if !ok || !cursor.owner().openedOn(sourceId, linkName) {
+		c.mu.Unlock()
+		return c.notFound
+	}
+	delete(c.cursors, handle)
+	c.mu.Unlock()
+	return cursor.close()
+}
+
+// closeLink closes every cursor that was opened on a link
+func (c *cursors[C]) closeLink(sourceId, linkName string) {
+	c.closeMatching(func(cursor C) bool {
+		return cursor.owner().openedOn(sourceId, linkName)
+	})
+}
+
+// closeAll closes every open cursor
+func (c *cursors[C]) closeAll() {
+	c.closeMatching(func(C) bool { return true })
+}
+
+// expire closes every cursor that has been idle for longer than its link allows
+func (c *cursors[C]) expire(now time.Time) int {
+	return c.closeMatching(func(cursor C) bool {
+		return cursor.owner().expired(now)
+	})
+}
+
+func (c *cursors[C]) closeMatching(match func(C) bool) int {
+	c.mu.Lock()
+	var closing []C
+	for handle, cursor := range c.cursors {
+		if match(cursor) {
+			closing = append(closing, cursor)
+			delete(c.cursors, handle)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, cursor := range closing {
+		_ = cursor.close()
+	}
+	return len(closing)
+}
+
+// reap periodically expires abandoned cursors until the context is cancelled
+func (c *cursors[C]) reap(ctx context.Context, onExpired func(count int)) {
+	ticker := time.NewTicker(cursorReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if count := c.expire(now); count > 0 {
+				onExpired(count)
+			}
+		}
+	}
+}
+
+func (o *cursorOwner) owner() *cursorOwner {
+	return o
+}
+
+func (o *cursorOwner) openedOn(sourceId, linkName string) bool {
+	return o.sourceId == sourceId && o.linkName == linkName
+}
+
+func (o *cursorOwner) expired(now time.Time) bool {
+	// A cursor that is being read from is in use, regardless of when it was last used
+	if !o.mu.TryLock() {
+		return false
+	}
+	defer o.mu.Unlock()
+	return now.Sub(o.lastUsed) > o.idleTimeout
+}
+
+// next reads up to max rows from the cursor, reporting whether all rows have been read
+func (q *queryCursor) next(max uint32) ([]json.RawMessage, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, true, errQueryCursorNotFound
+	}
+	q.lastUsed = time.Now()
+
+	max = min(max, cursorBatchLimit)
+	rows := make([]json.RawMessage, 0, max)
+	for !q.done && uint32(len(rows)) < max {
+		if !q.result.Next() {
+			q.done = true
+			if err := q.result.Err(); err != nil {
+				return nil, true, err
+			}
+			break
+		}
+		var row json.RawMessage
+		if err := q.result.Row(&row); err != nil {
+			return nil, q.done, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, q.done, nil
+}
+
+// metadata returns the metadata of the query, which is only available once all rows have been read
+func (q *queryCursor) metadata() (*gocb.QueryMetaData, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return nil, true, errQueryCursorNotFound
+	}
+	q.lastUsed = time.Now()
+	if !q.done {
+		return nil, false, nil
+	}
+	metadata, err := q.result.MetaData()
+	return metadata, true, err
+}
+
+func (q *queryCursor) close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	return q.result.Close()
+}
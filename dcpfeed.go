@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/couchbase/gocbcore/v10"
+	"github.com/couchbase/gocbcore/v10/memd"
+	"github.com/google/uuid"
+)
+
+// How long to wait for the DCP connection to the bucket to be established
+const dcpConnectTimeout = 10 * time.Second
+
+var errWatchedCollectionDropped = errors.New("watched collection was dropped")
+
+// A change feed streaming the mutations of the collection of a link over DCP
+//
+// Each run opens its own DCP connection, with one stream per partition filtered on the collection.
+type dcpFeed struct {
+	link *linkConnection
+}
+
+func newDcpFeed(link *linkConnection) *dcpFeed {
+	return &dcpFeed{link: link}
+}
+
+func (f *dcpFeed) run(ctx context.Context, from feedCheckpoint, handle func(context.Context, documentChange)) error {
+	connection, err := f.link.await(ctx, f.link.connectionArgs.ReadyTimeout)
+	if err != nil {
+		return err
+	}
+	collectionId, err := dcpCollectionId(connection.Collection)
+	if err != nil {
+		return fmt.Errorf("unable to resolve the watched collection: %w", err)
+	}
+
+	agent, err := f.connect(connection)
+	if err != nil {
+		return err
+	}
+	defer agent.Close()
+
+	snapshot, err := agent.ConfigSnapshot()
+	if err != nil {
+		return err
+	}
+	numPartitions, err := snapshot.NumVbuckets()
+	if err != nil {
+		return err
+	}
+
+	streams := &dcpStreams{
+		ctx:           ctx,
+		agent:         agent,
+		collectionId:  collectionId,
+		handle:        handle,
+		partitionUUID: make(map[uint16]gocbcore.VbUUID, numPartitions),
+		failed:        make(chan error, 1),
+	}
+	for partition := 0; partition < numPartitions; partition++ {
+		streams.open(uint16(partition), from[uint16(partition)])
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-streams.failed:
+		return err
+	}
+}
+
+// dcpCollectionId looks up the ID used to filter the streams on the watched collection
+func dcpCollectionId(collection *gocb.Collection) (uint32, error) {
+	router, err := collection.Bucket().Internal().IORouter()
+	if err != nil {
+		return 0, err
+	}
+	type lookup struct {
+		result *gocbcore.GetCollectionIDResult
+		err    error
+	}
+	done := make(chan lookup, 1)
+	_, err = router.GetCollectionID(collection.ScopeName(), collection.Name(), gocbcore.GetCollectionIDOptions{
+		Deadline: time.Now().Add(dcpConnectTimeout),
+	}, func(result *gocbcore.GetCollectionIDResult, err error) {
+		done <- lookup{result, err}
+	})
+	if err != nil {
+		return 0, err
+	}
+	result := <-done
+	if result.err != nil {
+		return 0, result.err
+	}
+	return result.result.CollectionID, nil
+}
+
+// connect opens a DCP connection to the bucket of the watched collection, authenticated like the cluster
+// the link is connected to
+func (f *dcpFeed) connect(connection *CouchbaseConnection) (*gocbcore.DCPAgent, error) {
+	config := gocbcore.DCPAgentConfig{}
+	if err := config.FromConnStr(f.link.connectionArgs.ConnectionString); err != nil {
+		return nil, err
+	}
+	config.UserAgent = "wasmcloud-provider-couchbase"
+	config.BucketName = connection.Collection.Bucket().Name()
+	config.SecurityConfig.Auth = dcpAuthProvider{connection.lease.auth}
+	config.IoConfig.UseCollections = true
+	// Values are delivered to components as-is
+	config.CompressionConfig.Enabled = false
+	// Expirations are otherwise reported as deletions
+	config.DCPConfig.UseExpiryOpcode = true
+
+	// Stream names must be unique per connection to the cluster
+	agent, err := gocbcore.CreateDcpAgent(&config, "wasmcloud-provider-couchbase-"+uuid.NewString(), memd.DcpOpenFlagProducer)
+	if err != nil {
+		return nil, err
+	}
+
+	ready := make(chan error, 1)
+	_, err = agent.WaitUntilReady(time.Now().Add(dcpConnectTimeout), gocbcore.WaitUntilReadyOptions{}, func(_ *gocbcore.WaitUntilReadyResult, err error) {
+		ready <- err
+	})
+	if err == nil {
+		err = <-ready
+	}
+	if err != nil {
+		_ = agent.Close()
+		return nil, err
+	}
+	return agent, nil
+}
+
+// The partition streams of a single run of a DCP feed, observing the changes of all partitions
+type dcpStreams struct {
+	ctx          context.Context
+	agent        *gocbcore.DCPAgent
+	collectionId uint32
+	handle       func(context.Context, documentChange)
+
+	// Current UUID of each partition, reported when its stream is opened
+	mu            sync.Mutex
+	partitionUUID map[uint16]gocbcore.VbUUID
+
+	// Receives the first error that ended a stream
+	failed chan error
+}
+
+// open streams the changes of a partition made after a position
+func (s *dcpStreams) open(partition uint16, from feedPosition) {
+	seq := gocbcore.SeqNo(from.Seq)
+	_, err := s.agent.OpenStream(partition, 0, gocbcore.VbUUID(from.PartitionUUID), seq, gocbcore.SeqNo(math.MaxUint64), seq, seq, s,
+		gocbcore.OpenStreamOptions{
+			FilterOptions: &gocbcore.OpenStreamFilterOptions{CollectionIDs: []uint32{s.collectionId}},
+		},
+		func(failoverLog []gocbcore.FailoverEntry, err error) {
+			var rollbackErr gocbcore.DCPRollbackError
+			if errors.As(err, &rollbackErr) {
+				// Changes after the position were lost in a failover
+				go s.rollback(partition, uint64(rollbackErr.SeqNo))
+				return
+			}
+			if err != nil {
+				s.fail(fmt.Errorf("unable to open stream for partition %d: %w", partition, err))
+				return
+			}
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			if len(failoverLog) > 0 {
+				s.partitionUUID[partition] = failoverLog[0].VbUUID
+			}
+		})
+	if err != nil {
+		s.fail(fmt.Errorf("unable to open stream for partition %d: %w", partition, err))
+	}
+}
+
+// rollback reopens the stream of a partition from the sequence number the server rolled it back to,
+// in the branch of the partition history that contains it
+func (s *dcpStreams) rollback(partition uint16, seq uint64) {
+	_, err := s.agent.GetFailoverLog(partition, func(failoverLog []gocbcore.FailoverEntry, err error) {
+		if err != nil {
+			s.fail(fmt.Errorf("unable to get failover log of partition %d: %w", partition, err))
+			return
+		}
+		// Entries are ordered from the newest branch, which is the newest that started at or before the sequence number.
+		// The history of the partition is replayed from the start if no branch contains it
+		var from feedPosition
+		for _, entry := range failoverLog {
+			if uint64(entry.SeqNo) <= seq {
+				from = feedPosition{PartitionUUID: uint64(entry.VbUUID), Seq: seq}
+				break
+			}
+		}
+		go s.open(partition, from)
+	})
+	if err != nil {
+		s.fail(fmt.Errorf("unable to get failover log of partition %d: %w", partition, err))
+	}
+}
+
+func (s *dcpStreams) fail(err error) {
+	select {
+	case s.failed <- err:
+	default:
+	}
+}
+
+func (s *dcpStreams) deliver(kind changeKind, partition uint16, key, value []byte, cas, seq uint64) {
+	s.mu.Lock()
+	partitionUUID := s.partitionUUID[partition]
+	s.mu.Unlock()
+	s.handle(s.ctx, documentChange{
+		kind:      kind,
+		id:        string(key),
+		value:     value,
+		cas:       cas,
+		partition: partition,
+		position:  feedPosition{PartitionUUID: uint64(partitionUUID), Seq: seq},
+	})
+}
+
+// Mutation implements gocbcore.StreamObserver.
+func (s *dcpStreams) Mutation(mutation gocbcore.DcpMutation) {
+	s.deliver(changeMutation, mutation.VbID, mutation.Key, mutation.Value, mutation.Cas, mutation.SeqNo)
+}
+
+// Deletion implements gocbcore.StreamObserver.
+func (s *dcpStreams) Deletion(deletion gocbcore.DcpDeletion) {
+	s.deliver(changeDeletion, deletion.VbID, deletion.Key, nil, deletion.Cas, deletion.SeqNo)
+}
+
+// Expiration implements gocbcore.StreamObserver.
+func (s *dcpStreams) Expiration(expiration gocbcore.DcpExpiration) {
+	s.deliver(changeExpiration, expiration.VbID, expiration.Key, nil, expiration.Cas, expiration.SeqNo)
+}
+
+// End implements gocbcore.StreamObserver.
+func (s *dcpStreams) End(end gocbcore.DcpStreamEnd, err error) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		err = errors.New("stream ended")
+	}
+	s.fail(fmt.Errorf("stream for partition %d ended: %w", end.VbID, err))
+}
+
+// DeleteCollection implements gocbcore.StreamObserver.
+func (s *dcpStreams) DeleteCollection(deletion gocbcore.DcpCollectionDeletion) {
+	if deletion.CollectionID == s.collectionId {
+		s.fail(errWatchedCollectionDropped)
+	}
+}
+
+// FlushCollection implements gocbcore.StreamObserver.
+func (s *dcpStreams) FlushCollection(gocbcore.DcpCollectionFlush) {}
+
+// SnapshotMarker implements gocbcore.StreamObserver.
+func (s *dcpStreams) SnapshotMarker(gocbcore.DcpSnapshotMarker) {}
+
+// CreateCollection implements gocbcore.StreamObserver.
+func (s *dcpStreams) CreateCollection(gocbcore.DcpCollectionCreation) {}
+
+// CreateScope implements gocbcore.StreamObserver.
+func (s *dcpStreams) CreateScope(gocbcore.DcpScopeCreation) {}
+
+// DeleteScope implements gocbcore.StreamObserver.
+func (s *dcpStreams) DeleteScope(gocbcore.DcpScopeDeletion) {}
+
+// ModifyCollection implements gocbcore.StreamObserver.
+func (s *dcpStreams) ModifyCollection(gocbcore.DcpCollectionModification) {}
+
+// OSOSnapshot implements gocbcore.StreamObserver.
+func (s *dcpStreams) OSOSnapshot(gocbcore.DcpOSOSnapshot) {}
+
+// SeqNoAdvanced implements gocbcore.StreamObserver.
+func (s *dcpStreams) SeqNoAdvanced(gocbcore.DcpSeqNoAdvanced) {}
+
+// Authenticates DCP connections with the credentials of a shared cluster, so that they follow its rotations
+type dcpAuthProvider struct {
+	auth *rotatingAuthenticator
+}
+
+func (a dcpAuthProvider) credentials() gocbcore.PasswordAuthProvider {
+	return gocbcore.PasswordAuthProvider{Username: a.auth.Username, Password: a.auth.password()}
+}
+
+// SupportsTLS implements gocbcore.AuthProvider.
+func (a dcpAuthProvider) SupportsTLS() bool { return a.credentials().SupportsTLS() }
+
+// SupportsNonTLS implements gocbcore.AuthProvider.
+func (a dcpAuthProvider) SupportsNonTLS() bool { return a.credentials().SupportsNonTLS() }
+
+// Certificate implements gocbcore.AuthProvider.
+func (a dcpAuthProvider) Certificate(req gocbcore.AuthCertRequest) (*tls.Certificate, error) {
+	return a.credentials().Certificate(req)
+}
+
+// Credentials implements gocbcore.AuthProvider.
+func (a dcpAuthProvider) Credentials(req gocbcore.AuthCredsRequest) ([]gocbcore.UserPassPair, error) {
+	return a.credentials().Credentials(req)
+}
@@ -33,10 +33,20 @@ type Handler struct {
 	// All components linked to this provider and their config.
 	// linkedFrom map[string]map[string]string
 
-	// Map that stores couchbase cluster connections
-	// The map is of the following structure:
-	// sourceID -> linkName -> collection connection
-	clusterConnections map[string]map[string]*gocb.Collection
+	// Couchbase cluster connections of the links to this provider, which may still be connecting
+	links *linkRegistry
+	// Cluster connections shared by the links connecting with the same identity
+	clusters *clusterPool
+	// Open SQL++ query cursors, keyed by handle
+	queryCursors *queryCursors
+	// Open KV scan cursors, keyed by handle
+	scanCursors *scanCursors
+	// Open wrpc:keyvalue list-keys scans, keyed by cursor
+	keyListings *scanCursors
+	// Open transactions, keyed by token
+	transactions *txRegistry
+	// Change feeds delivered to components linked from this provider, keyed by target and link name
+	changeSubscriptions *subscriptionRegistry
 }
 
 func (h *Handler) Get(ctx context.Context, id string, options *document.DocumentGetOptions) (*wrpc.Result[document.DocumentGetResult, types.DocumentError], error) {
@@ -239,12 +249,58 @@ func (h *Handler) Upsert(ctx context.Context, id string, doc *types.Document, op
 	return Ok(MutationMetadata(result)), nil
 }
 
+// Exists implements document.Handler.
+func (h *Handler) Exists(ctx context.Context, id string, options *document.DocumentExistsOptions) (*wrpc.Result[document.DocumentExistsResult, types.DocumentError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	result, err := collection.Exists(id, ExistsOptions(options))
+	if err != nil {
+		h.Logger.Error("Error checking document existence", "error", err)
+		return nil, err
+	}
+	return Ok(ExistsResult(result)), nil
+}
+
 // Helper function to get the correct collection from the invocation context
 func (h *Handler) getCollectionFromContext(ctx context.Context) (*gocb.Collection, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return connection.Collection, nil
+}
+
+// Helper function to get the cluster connection of the link the invocation was made on
+func (h *Handler) getConnectionFromContext(ctx context.Context) (*CouchbaseConnection, error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	link, exists := h.links.get(sourceId, linkName)
+	if !exists {
+		h.Logger.Warn("Received request from unlinked source", "sourceId", sourceId, "linkName", linkName)
+		return nil, fmt.Errorf("%w %s with link name %s", errUnlinkedSource, sourceId, linkName)
+	}
+
+	// Requests made while the link is connecting wait for it, up to the ready timeout of the link
+	connection, err := link.await(ctx, link.connectionArgs.ReadyTimeout)
+	if err != nil {
+		h.Logger.Warn("Received request on link that is not ready", "sourceId", sourceId, "linkName", linkName, "error", err)
+		return nil, err
+	}
+	return connection, nil
+}
+
+// Helper function to get the source ID and link name the invocation was made on
+func (h *Handler) getLinkFromContext(ctx context.Context) (string, string, error) {
 	header, ok := wrpcnats.HeaderFromContext(ctx)
 	if !ok {
 		h.Logger.Warn("error fetching header from wrpc context")
-		return nil, errors.New("error fetching header from wrpc context")
+		return "", "", errors.New("error fetching header from wrpc context")
 	}
 	// Only allow requests from a linked component
 	sourceId := header.Get("source-id")
@@ -252,12 +308,21 @@ func (h *Handler) getCollectionFromContext(ctx context.Context) (*gocb.Collectio
 	if linkName == "" {
 		linkName = "default"
 	}
+	return sourceId, linkName, nil
+}
 
-	if h.clusterConnections[sourceId] == nil || h.clusterConnections[sourceId][linkName] == nil {
-		h.Logger.Warn("Received request from unlinked source", "sourceId", sourceId, "linkName", linkName)
-		return nil, fmt.Errorf("received request from unlinked source %s with link name %s", sourceId, linkName)
+// DocumentError maps errors of document operations to their document error, if any
+func DocumentError(err error) *types.DocumentError {
+	switch {
+	case errors.Is(err, gocb.ErrDocumentNotFound):
+		return types.NewDocumentErrorNotFound()
+	case errors.Is(err, gocb.ErrDocumentExists):
+		return types.NewDocumentErrorAlreadyExists()
+	case errors.Is(err, gocb.ErrCasMismatch):
+		return types.NewDocumentErrorCasMismatch()
+	case errors.Is(err, gocb.ErrDocumentLocked):
+		return types.NewDocumentErrorLocked()
+	default:
+		return nil
 	}
-
-	// NOTE: We just checked if this is nil above, so it's ensured that the collection is not nil
-	return h.clusterConnections[sourceId][linkName], nil
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/batch"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/store"
+	gocbt "github.com/couchbase/gocb-opentelemetry"
+)
+
+// How many keys of a batch operation are processed concurrently
+const batchConcurrency = 16
+
+// Implementation of wasi:keyvalue/batch
+
+func (h *Handler) GetMany(ctx context.Context, bucket string, keys []string) (*wrpc.Result[[]*wrpc.Tuple2[string, []uint8], batch.Error], error) {
+	ctx = extractTraceHeaderContext(ctx)
+	ctx, span := tracer.Start(ctx, "GET-MANY")
+	defer span.End()
+
+	h.Logger.Debug("received request to get values", "count", len(keys))
+	collection, err := h.getCollectionFromContext(ctx, bucket)
+	if err != nil {
+		h.Logger.Error("unable to get collection from context", "error", err)
+		return wrpc.Err[[]*wrpc.Tuple2[string, []uint8]](*errNoSuchStore), nil
+	}
+
+	// Missing keys are reported as `none`, in the position of the key
+	values := make([]*wrpc.Tuple2[string, []uint8], len(keys))
+	err = forEachKey(len(keys), func(idx int) error {
+		res, err := collection.Get(keys[idx], &gocb.GetOptions{
+			Transcoder: gocb.NewRawJSONTranscoder(),
+			ParentSpan: gocbt.NewOpenTelemetryRequestSpan(ctx, span),
+		})
+		if errors.Is(err, gocb.ErrDocumentNotFound) {
+			return nil
+		}
+		if err != nil {
+			h.Logger.Error("unable to get value in store", "key", keys[idx], "error", err)
+			return err
+		}
+		var value []uint8
+		if err := res.Content(&value); err != nil {
+			h.Logger.Error("unable to decode content as bytes", "key", keys[idx], "error", err)
+			return err
+		}
+		values[idx] = &wrpc.Tuple2[string, []uint8]{V0: keys[idx], V1: value}
+		return nil
+	})
+	if err != nil {
+		return wrpc.Err[[]*wrpc.Tuple2[string, []uint8]](*store.NewErrorOther(err.Error())), nil
+	}
+	return wrpc.Ok[batch.Error](values), nil
+}
+
+func (h *Handler) SetMany(ctx context.Context, bucket string, keyValues []*wrpc.Tuple2[string, []uint8]) (*wrpc.Result[struct{}, batch.Error], error) {
+	ctx = extractTraceHeaderContext(ctx)
+	ctx, span := tracer.Start(ctx, "SET-MANY")
+	defer span.End()
+
+	h.Logger.Debug("received request to set values", "count", len(keyValues))
+	collection, err := h.getCollectionFromContext(ctx, bucket)
+	if err != nil {
+		h.Logger.Error("unable to get collection from context", "error", err)
+		return wrpc.Err[struct{}](*errNoSuchStore), nil
+	}
+
+	// Values that were set before an error occurred are not rolled back
+	err = forEachKey(len(keyValues), func(idx int) error {
+		key, value := keyValues[idx].V0, keyValues[idx].V1
+		_, err := collection.Upsert(key, &value, &gocb.UpsertOptions{
+			Transcoder: gocb.NewRawJSONTranscoder(),
+			ParentSpan: gocbt.NewOpenTelemetryRequestSpan(ctx, span),
+		})
+		if err != nil {
+			h.Logger.Error("unable to store value", "key", key, "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		return wrpc.Err[struct{}](*store.NewErrorOther(err.Error())), nil
+	}
+	return wrpc.Ok[batch.Error](struct{}{}), nil
+}
+
+func (h *Handler) DeleteMany(ctx context.Context, bucket string, keys []string) (*wrpc.Result[struct{}, batch.Error], error) {
+	ctx = extractTraceHeaderContext(ctx)
+	ctx, span := tracer.Start(ctx, "DELETE-MANY")
+	defer span.End()
+
+	h.Logger.Debug("received request to delete values", "count", len(keys))
+	collection, err := h.getCollectionFromContext(ctx, bucket)
+	if err != nil {
+		h.Logger.Error("unable to get collection from context", "error", err)
+		return wrpc.Err[struct{}](*errNoSuchStore), nil
+	}
+
+	// Missing keys are skipped, values that were deleted before an error occurred are not restored
+	err = forEachKey(len(keys), func(idx int) error {
+		_, err := collection.Remove(keys[idx], &gocb.RemoveOptions{
+			ParentSpan: gocbt.NewOpenTelemetryRequestSpan(ctx, span),
+		})
+		if errors.Is(err, gocb.ErrDocumentNotFound) {
+			return nil
+		}
+		if err != nil {
+			h.Logger.Error("unable to remove value", "key", keys[idx], "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		return wrpc.Err[struct{}](*store.NewErrorOther(err.Error())), nil
+	}
+	return wrpc.Ok[batch.Error](struct{}{}), nil
+}
+
+// forEachKey runs an operation for each of count keys, at most batchConcurrency at a time,
+// returning the errors of the operations that failed
+func forEachKey(count int, op func(idx int) error) error {
+	errs := make([]error, count)
+	slots := make(chan struct{}, batchConcurrency)
+	var wg sync.WaitGroup
+	for idx := 0; idx < count; idx++ {
+		slots <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			errs[idx] = op(idx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
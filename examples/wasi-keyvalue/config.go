@@ -2,6 +2,8 @@ package main
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"go.wasmcloud.dev/provider"
 )
@@ -13,6 +15,14 @@ type CouchbaseConnectionArgs struct {
 	ConnectionString string
 	ScopeName        string
 	CollectionName   string
+	// Keyspaces of the bucket that stores are opened on, keyed by alias
+	KeyvalueStores map[string]KeyvalueKeyspace
+}
+
+// A collection of the bucket of a link, used as a keyvalue store
+type KeyvalueKeyspace struct {
+	ScopeName      string
+	CollectionName string
 }
 
 // Construct Couchbase connection args from config and secrets
@@ -40,5 +50,41 @@ func validateCouchbaseConfig(config map[string]string, secrets map[string]provid
 	} else {
 		connectionArgs.Password = password
 	}
+
+	// Store aliases are optional, stores can also be opened by keyspace
+	if keyvalueStores, ok := config["keyvalueStores"]; ok && keyvalueStores != "" {
+		stores, err := parseKeyvalueStores(keyvalueStores)
+		if err != nil {
+			return connectionArgs, fmt.Errorf("keyvalueStores must be a list of alias=scope.collection pairs: %w", err)
+		}
+		connectionArgs.KeyvalueStores = stores
+	}
 	return connectionArgs, nil
 }
+
+// parseKeyvalueStores parses comma separated alias=scope.collection pairs
+func parseKeyvalueStores(value string) (map[string]KeyvalueKeyspace, error) {
+	stores := make(map[string]KeyvalueKeyspace)
+	for _, pair := range strings.Split(value, ",") {
+		alias, keyspace, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		alias = strings.TrimSpace(alias)
+		if !ok || alias == "" {
+			return nil, fmt.Errorf("invalid store '%s'", pair)
+		}
+		parsed, ok := parseKeyvalueKeyspace(strings.TrimSpace(keyspace))
+		if !ok {
+			return nil, fmt.Errorf("invalid keyspace for store '%s'", alias)
+		}
+		stores[alias] = parsed
+	}
+	return stores, nil
+}
+
+// parseKeyvalueKeyspace parses a scope.collection keyspace
+func parseKeyvalueKeyspace(keyspace string) (KeyvalueKeyspace, bool) {
+	scopeName, collectionName, ok := strings.Cut(keyspace, ".")
+	if !ok || scopeName == "" || collectionName == "" || strings.Contains(collectionName, ".") {
+		return KeyvalueKeyspace{}, false
+	}
+	return KeyvalueKeyspace{ScopeName: scopeName, CollectionName: collectionName}, true
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+const (
+	// Maximum number of keys returned by a single list-keys call
+	listKeysPageSize = 1000
+	// Listings that are not continued within this long are closed
+	listKeysIdleTimeout = 5 * time.Minute
+	// How often listings abandoned by components are looked for
+	listKeysReapInterval = 30 * time.Second
+)
+
+var errListingNotFound = errors.New("list-keys cursor does not exist")
+
+// An ids-only scan of a collection, continued by successive list-keys calls
+type listing struct {
+	sourceId string
+	result   *gocb.ScanResult
+	lastUsed time.Time
+}
+
+// Listings that have more keys to return, keyed by the cursor handed out to components
+type listings struct {
+	mu         sync.Mutex
+	nextCursor uint64
+	open       map[uint64]*listing
+}
+
+func newListings() *listings {
+	return &listings{open: make(map[uint64]*listing)}
+}
+
+// startListing begins listing every key of a collection
+func startListing(sourceId string, collection *gocb.Collection, options *gocb.ScanOptions) (*listing, error) {
+	options.IDsOnly = true
+	result, err := collection.Scan(gocb.RangeScan{}, options)
+	if err != nil {
+		return nil, err
+	}
+	return &listing{sourceId: sourceId, result: result}, nil
+}
+
+// resume takes back a listing that was suspended by a source
+func (l *listings) resume(sourceId string, cursor uint64) (*listing, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expireLocked(time.Now())
+	listing, ok := l.open[cursor]
+	if !ok || listing.sourceId != sourceId {
+		return nil, errListingNotFound
+	}
+	delete(l.open, cursor)
+	return listing, nil
+}
+
+// suspend keeps a listing that has more keys, returning the cursor to continue it with
+func (l *listings) suspend(listing *listing) uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.nextCursor++
+	listing.lastUsed = time.Now()
+	l.open[l.nextCursor] = listing
+	return l.nextCursor
+}
+
+// closeSource closes every listing started by a source
+func (l *listings) closeSource(sourceId string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for cursor, listing := range l.open {
+		if listing.sourceId == sourceId {
+			_ = listing.result.Close()
+			delete(l.open, cursor)
+		}
+	}
+}
+
+// expire closes every listing that has not been continued within listKeysIdleTimeout
+func (l *listings) expire(now time.Time) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.expireLocked(now)
+}
+
+func (l *listings) expireLocked(now time.Time) int {
+	count := 0
+	for cursor, listing := range l.open {
+		if now.Sub(listing.lastUsed) > listKeysIdleTimeout {
+			_ = listing.result.Close()
+			delete(l.open, cursor)
+			count++
+		}
+	}
+	return count
+}
+
+// reap periodically expires abandoned listings until the context is cancelled
+func (l *listings) reap(ctx context.Context, onExpired func(count int)) {
+	ticker := time.NewTicker(listKeysReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if count := l.expire(now); count > 0 {
+				onExpired(count)
+			}
+		}
+	}
+}
+
+// next reads up to max keys, reporting whether all keys have been read
+func (l *listing) next(max int) ([]string, bool, error) {
+	keys := make([]string, 0, max)
+	for len(keys) < max {
+		item := l.result.Next()
+		if item == nil {
+			return keys, true, l.result.Err()
+		}
+		keys = append(keys, item.ID())
+	}
+	return keys, false, nil
+}
@@ -43,6 +43,8 @@ func run() error {
 	providerHandler := Handler{
 		linkedFrom:         make(map[string]map[string]string),
 		clusterConnections: make(map[string]*gocb.Collection),
+		keyvalueStores:     make(map[string]map[string]KeyvalueKeyspace),
+		listings:           newListings(),
 	}
 
 	p, err := provider.New(
@@ -63,12 +65,17 @@ func run() error {
 	signalCh := make(chan os.Signal, 1)
 
 	// Handle RPC operations
-	stopFunc, err := server.Serve(p.RPCClient, &providerHandler, &providerHandler)
+	stopFunc, err := server.Serve(p.RPCClient, &providerHandler, &providerHandler, &providerHandler)
 	if err != nil {
 		p.Shutdown()
 		return err
 	}
 
+	// Close key listings abandoned by components
+	go providerHandler.listings.reap(ctx, func(count int) {
+		providerHandler.Logger.Info("Closed idle key listings", "count", count)
+	})
+
 	// Handle control interface operations
 	go func() {
 		err := p.Start()
@@ -93,12 +100,17 @@ func run() error {
 // Provider handler functions
 func (h *Handler) handleNewTargetLink(link provider.InterfaceLinkDefinition) error {
 	h.Logger.Info("Handling new target link", "link", link)
+	h.mu.Lock()
 	h.linkedFrom[link.SourceID] = link.TargetConfig
+	h.mu.Unlock()
 	couchbaseConnectionArgs, err := validateCouchbaseConfig(link.TargetConfig, link.TargetSecrets)
 	if err != nil {
 		h.Logger.Error("Invalid couchbase target config", "error", err)
 		return err
 	}
+	h.mu.Lock()
+	h.keyvalueStores[link.SourceID] = couchbaseConnectionArgs.KeyvalueStores
+	h.mu.Unlock()
 	h.updateCouchbaseCluster(link.SourceID, couchbaseConnectionArgs)
 	return nil
 }
@@ -127,12 +139,18 @@ func (h *Handler) updateCouchbaseCluster(sourceId string, connectionArgs Couchba
 	}
 
 	// Store the connection
+	h.mu.Lock()
 	h.clusterConnections[sourceId] = collection
+	h.mu.Unlock()
 }
 
 func (h *Handler) handleDelTargetLink(link provider.InterfaceLinkDefinition) error {
 	h.Logger.Info("Handling del target link", "link", link)
-	delete(h.linkedFrom, link.Target)
+	h.listings.closeSource(link.SourceID)
+	h.mu.Lock()
+	delete(h.linkedFrom, link.SourceID)
+	delete(h.keyvalueStores, link.SourceID)
+	h.mu.Unlock()
 	return nil
 }
 
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"github.com/couchbase/gocb/v2"
 	sdk "go.wasmcloud.dev/provider"
@@ -24,16 +25,26 @@ var (
 	tracer             trace.Tracer
 )
 
+// Identifier of the store on the collection configured on the link
+const defaultStore = "default"
+
 // This provider `Handler` stores a global collection for querying.
 // TODO(#): Support storing connections per linked component
 type Handler struct {
 	// The provider instance
 	*sdk.WasmcloudProvider
+
+	// Guards the maps of linked components, which links are put into and deleted from while requests are served
+	mu sync.RWMutex
 	// All components linked to this provider and their config.
 	linkedFrom map[string]map[string]string
 
 	// map that stores couchbase cluster connections
 	clusterConnections map[string]*gocb.Collection
+	// keyvalue store aliases configured on the link of each source
+	keyvalueStores map[string]map[string]KeyvalueKeyspace
+	// list-keys scans that have more keys to return
+	listings *listings
 }
 
 // Implementation of wasi:keyvalue/store
@@ -44,10 +55,10 @@ func (h *Handler) Get(ctx context.Context, bucket string, key string) (*wrpc.Res
 	defer span.End()
 
 	h.Logger.Debug("received request to get value", "key", key)
-	collection, err := h.getCollectionFromContext(ctx)
+	collection, err := h.getCollectionFromContext(ctx, bucket)
 	if err != nil {
 		h.Logger.Error("unable to get collection from context", "error", err)
-		return wrpc.Err[[]uint8](*errNoSuchStore), err
+		return wrpc.Err[[]uint8](*errNoSuchStore), nil
 	}
 
 	res, err := collection.Get(key, &gocb.GetOptions{
@@ -68,19 +79,54 @@ func (h *Handler) Get(ctx context.Context, bucket string, key string) (*wrpc.Res
 	return wrpc.Ok[store.Error](response), nil
 }
 
-func (h *Handler) getCollectionFromContext(ctx context.Context) (*gocb.Collection, error) {
+// getCollectionFromContext returns the collection of a store, for the source the invocation was made from
+func (h *Handler) getCollectionFromContext(ctx context.Context, bucket string) (*gocb.Collection, error) {
+	sourceId, err := h.getSourceFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.resolveStore(sourceId, bucket)
+}
+
+// resolveStore resolves the identifier of a store to a collection, which is either aliased in the
+// link config, the collection of the link for the default store (or an empty identifier), or a
+// scope.collection keyspace of the bucket of the link
+func (h *Handler) resolveStore(sourceId string, bucket string) (*gocb.Collection, error) {
+	h.mu.RLock()
+	collection := h.clusterConnections[sourceId]
+	keyspace, ok := h.keyvalueStores[sourceId][bucket]
+	h.mu.RUnlock()
+	if collection == nil {
+		return nil, errors.New("source is not connected")
+	}
+	if !ok {
+		if bucket == "" || bucket == defaultStore {
+			return collection, nil
+		}
+		if keyspace, ok = parseKeyvalueKeyspace(bucket); !ok {
+			h.Logger.Warn("Received request for unknown store", "bucket", bucket)
+			return nil, errors.New("unknown store")
+		}
+	}
+	return collection.Bucket().Scope(keyspace.ScopeName).Collection(keyspace.CollectionName), nil
+}
+
+func (h *Handler) getSourceFromContext(ctx context.Context) (string, error) {
 	header, ok := wrpcnats.HeaderFromContext(ctx)
 	if !ok {
 		h.Logger.Warn("Received request from unknown origin")
-		return nil, errors.New("error fetching header from wrpc context")
+		return "", errors.New("error fetching header from wrpc context")
 	}
 	// Only allow requests from a linked component
 	sourceId := header.Get("source-id")
-	if h.linkedFrom[sourceId] == nil {
+	h.mu.RLock()
+	linked := h.linkedFrom[sourceId] != nil
+	h.mu.RUnlock()
+	if !linked {
 		h.Logger.Warn("Received request from unlinked source", "sourceId", sourceId)
-		return nil, errors.New("received request from unlinked source")
+		return "", errors.New("received request from unlinked source")
 	}
-	return h.clusterConnections[sourceId], nil
+	return sourceId, nil
 }
 
 func (h *Handler) Set(ctx context.Context, bucket string, key string, value []uint8) (*wrpc.Result[struct{}, store.Error], error) {
@@ -89,10 +135,10 @@ func (h *Handler) Set(ctx context.Context, bucket string, key string, value []ui
 	defer span.End()
 
 	h.Logger.Debug("received request to set value", "key", key)
-	collection, err := h.getCollectionFromContext(ctx)
+	collection, err := h.getCollectionFromContext(ctx, bucket)
 	if err != nil {
 		h.Logger.Error("unable to get collection from context", "error", err)
-		return wrpc.Err[struct{}](*errNoSuchStore), err
+		return wrpc.Err[struct{}](*errNoSuchStore), nil
 	}
 
 	_, err = collection.Upsert(key, &value, &gocb.UpsertOptions{
@@ -112,10 +158,10 @@ func (h *Handler) Delete(ctx context.Context, bucket string, key string) (*wrpc.
 	defer span.End()
 
 	h.Logger.Debug("received request to delete value", "key", key)
-	collection, err := h.getCollectionFromContext(ctx)
+	collection, err := h.getCollectionFromContext(ctx, bucket)
 	if err != nil {
 		h.Logger.Error("unable to get collection from context", "error", err)
-		return wrpc.Err[struct{}](*errNoSuchStore), err
+		return wrpc.Err[struct{}](*errNoSuchStore), nil
 	}
 
 	_, err = collection.Remove(key, &gocb.RemoveOptions{
@@ -134,10 +180,10 @@ func (h *Handler) Exists(ctx context.Context, bucket string, key string) (*wrpc.
 	defer span.End()
 
 	h.Logger.Debug("received request to check value existence", "key", key)
-	collection, err := h.getCollectionFromContext(ctx)
+	collection, err := h.getCollectionFromContext(ctx, bucket)
 	if err != nil {
 		h.Logger.Error("unable to get collection from context", "error", err)
-		return wrpc.Err[bool](*errNoSuchStore), err
+		return wrpc.Err[bool](*errNoSuchStore), nil
 	}
 
 	res, err := collection.Exists(key, &gocb.ExistsOptions{
@@ -151,8 +197,51 @@ func (h *Handler) Exists(ctx context.Context, bucket string, key string) (*wrpc.
 }
 
 func (h *Handler) ListKeys(ctx context.Context, bucket string, cursor *uint64) (*wrpc.Result[store.KeyResponse, store.Error], error) {
-	h.Logger.Warn("received request to list keys")
-	return wrpc.Err[store.KeyResponse](*store.NewErrorOther("list-keys operation not supported")), nil
+	ctx = extractTraceHeaderContext(ctx)
+	ctx, span := tracer.Start(ctx, "LIST-KEYS")
+	defer span.End()
+
+	h.Logger.Debug("received request to list keys", "cursor", cursor)
+	sourceId, err := h.getSourceFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("unable to get source from context", "error", err)
+		return wrpc.Err[store.KeyResponse](*errNoSuchStore), nil
+	}
+
+	// Keys are listed with a single scan, suspended between pages so that it can be continued
+	var listing *listing
+	if cursor == nil {
+		collection, err := h.resolveStore(sourceId, bucket)
+		if err != nil {
+			h.Logger.Error("unable to get collection from context", "error", err)
+			return wrpc.Err[store.KeyResponse](*errNoSuchStore), nil
+		}
+		listing, err = startListing(sourceId, collection, &gocb.ScanOptions{
+			ParentSpan: gocbt.NewOpenTelemetryRequestSpan(ctx, span),
+		})
+		if err != nil {
+			h.Logger.Error("unable to scan keys", "error", err)
+			return wrpc.Err[store.KeyResponse](*store.NewErrorOther(err.Error())), nil
+		}
+	} else if listing, err = h.listings.resume(sourceId, *cursor); err != nil {
+		h.Logger.Error("unable to continue listing keys", "cursor", *cursor, "error", err)
+		return wrpc.Err[store.KeyResponse](*store.NewErrorOther(err.Error())), nil
+	}
+
+	keys, done, err := listing.next(listKeysPageSize)
+	if err != nil {
+		h.Logger.Error("unable to list keys", "error", err)
+		_ = listing.result.Close()
+		return wrpc.Err[store.KeyResponse](*store.NewErrorOther(err.Error())), nil
+	}
+	response := store.KeyResponse{Keys: keys}
+	if done {
+		_ = listing.result.Close()
+	} else {
+		next := h.listings.suspend(listing)
+		response.Cursor = &next
+	}
+	return wrpc.Ok[store.Error](response), nil
 }
 
 // Implementation of wasi:keyvalue/atomics
@@ -162,10 +251,10 @@ func (h *Handler) Increment(ctx context.Context, bucket string, key string, delt
 	defer span.End()
 
 	h.Logger.Debug("received request to increment key by delta", "key", key, "delta", delta)
-	collection, err := h.getCollectionFromContext(ctx)
+	collection, err := h.getCollectionFromContext(ctx, bucket)
 	if err != nil {
 		h.Logger.Error("unable to get collection from context", "error", err)
-		return wrpc.Err[uint64](*errNoSuchStore), err
+		return wrpc.Err[uint64](*errNoSuchStore), nil
 	}
 
 	res, err := collection.Binary().Increment(key, &gocb.IncrementOptions{
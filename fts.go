@@ -0,0 +1,659 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/couchbase/gocb/v2/search"
+	"github.com/couchbase/gocb/v2/vector"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/fts"
+)
+
+// Search implements fts.Handler.
+func (h *Handler) Search(ctx context.Context, index string, query *fts.SearchQuery, options *fts.SearchOptions) (*wrpc.Result[fts.SearchResult, fts.FtsSearchError], error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	request, raw, err := SearchRequest(query)
+	if err != nil {
+		h.Logger.Error("Error building search query", "error", err)
+		return wrpc.Err[fts.SearchResult](*fts.NewFtsSearchErrorInvalidArgument(err.Error())), nil
+	}
+	searchOptions, err := SearchOptions(options)
+	if err != nil {
+		h.Logger.Error("Error building search options", "error", err)
+		return wrpc.Err[fts.SearchResult](*fts.NewFtsSearchErrorInvalidArgument(err.Error())), nil
+	}
+	if raw != nil {
+		if searchOptions == nil {
+			searchOptions = &gocb.SearchOptions{}
+		}
+		searchOptions.Raw = raw
+	}
+
+	result, err := connection.search(index, request, searchOptions)
+	if err != nil {
+		h.Logger.Error("Error executing search", "error", err)
+		return wrpc.Err[fts.SearchResult](*FtsSearchError(err, index)), nil
+	}
+	searchResult, err := SearchResult(result)
+	if err != nil {
+		h.Logger.Error("Error reading search results", "error", err)
+		return wrpc.Err[fts.SearchResult](*FtsSearchError(err, index)), nil
+	}
+	return wrpc.Ok[fts.FtsSearchError](searchResult), nil
+}
+
+// search executes a search against the scope configured on the link,
+// falling back to the cluster when the link uses the default scope
+func (c *CouchbaseConnection) search(index string, request gocb.SearchRequest, options *gocb.SearchOptions) (*gocb.SearchResult, error) {
+	if scopeName := c.Collection.ScopeName(); scopeName != "_default" {
+		return c.Collection.Bucket().Scope(scopeName).Search(index, request, options)
+	}
+	return c.Cluster.Search(index, request, options)
+}
+
+// FtsSearchError maps errors returned by the search service to their FTS search error
+func FtsSearchError(err error, index string) *fts.FtsSearchError {
+	switch {
+	case errors.Is(err, gocb.ErrIndexNotFound):
+		return fts.NewFtsSearchErrorIndexNotFound(index)
+	case errors.Is(err, gocb.ErrTimeout):
+		return fts.NewFtsSearchErrorTimeout()
+	case errors.Is(err, gocb.ErrInvalidArgument):
+		return fts.NewFtsSearchErrorInvalidArgument(err.Error())
+	default:
+		return fts.NewFtsSearchErrorUnexpected(err.Error())
+	}
+}
+
+// SearchRequest builds the request for a search query, along with any raw parameters
+// that cannot be expressed through the request and must be added to the search options
+func SearchRequest(query *fts.SearchQuery) (gocb.SearchRequest, map[string]interface{}, error) {
+	if query == nil {
+		return gocb.SearchRequest{}, nil, errors.New("search query must not be empty")
+	}
+	switch query.Discriminant() {
+	case fts.SearchQueryText:
+		payload, _ := query.GetText()
+		searchQuery, err := TextSearchQuery(payload)
+		if err != nil {
+			return gocb.SearchRequest{}, nil, err
+		}
+		return gocb.SearchRequest{SearchQuery: searchQuery}, nil, nil
+	case fts.SearchQueryVector:
+		payload, _ := query.GetVector()
+		return VectorSearchRequest(payload)
+	default:
+		return gocb.SearchRequest{}, nil, fmt.Errorf("unsupported search query %s", query)
+	}
+}
+
+// VectorSearchRequest
+func VectorSearchRequest(q *fts.VectorSearchQuery) (gocb.SearchRequest, map[string]interface{}, error) {
+	var request gocb.SearchRequest
+	if q.Text != nil {
+		searchQuery, err := TextSearchQuery(q.Text)
+		if err != nil {
+			return gocb.SearchRequest{}, nil, err
+		}
+		request.SearchQuery = searchQuery
+	}
+
+	combination := VectorQueryCombination(q.Combination)
+	queries := make([]*vector.Query, 0, len(q.Queries))
+	prefilters := make([]search.Query, 0, len(q.Queries))
+	prefiltered := false
+	for _, vq := range q.Queries {
+		if vq == nil {
+			continue
+		}
+		query := vector.NewQuery(vq.Field, vq.Vector)
+		if vq.NumCandidates != nil {
+			query.NumCandidates(*vq.NumCandidates)
+		}
+		if vq.Boost != nil {
+			query.Boost(*vq.Boost)
+		}
+		if err := query.Internal().Validate(); err != nil {
+			return gocb.SearchRequest{}, nil, err
+		}
+		var prefilter search.Query
+		if vq.Prefilter != nil {
+			filter, err := TextSearchQuery(vq.Prefilter)
+			if err != nil {
+				return gocb.SearchRequest{}, nil, err
+			}
+			prefilter = filter
+			prefiltered = true
+		}
+		queries = append(queries, query)
+		prefilters = append(prefilters, prefilter)
+	}
+	if len(queries) == 0 {
+		return gocb.SearchRequest{}, nil, errors.New("at least one vector query must be specified")
+	}
+
+	if !prefiltered {
+		request.VectorSearch = vector.NewSearch(queries, &vector.SearchOptions{VectorQueryCombination: combination})
+		return request, nil, nil
+	}
+
+	// gocb has no support for pre-filtered vector queries, so the KNN part of the request is built by hand
+	knn := make([]knnQuery, 0, len(queries))
+	for idx, query := range queries {
+		knn = append(knn, knnQuery{InternalQuery: query.Internal(), Filter: prefilters[idx]})
+	}
+	raw := map[string]interface{}{"knn": knn}
+	if combination != vector.VectorQueryCombinationNotSet {
+		raw["knn_operator"] = string(combination)
+	}
+	return request, raw, nil
+}
+
+// A KNN query carrying a pre-filter, as sent to the search service
+type knnQuery struct {
+	vector.InternalQuery
+	Filter search.Query
+}
+
+func (q knnQuery) MarshalJSON() ([]byte, error) {
+	if q.NumCandidates == nil {
+		// Matches the number of candidates gocb defaults to
+		numCandidates := uint32(3)
+		q.NumCandidates = &numCandidates
+	}
+	encoded, err := q.InternalQuery.MarshalJSON()
+	if err != nil || q.Filter == nil {
+		return encoded, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+	filter, err := json.Marshal(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	fields["filter"] = filter
+	return json.Marshal(fields)
+}
+
+// VectorQueryCombination
+func VectorQueryCombination(m fts.VectorSearchCombinationMethod) vector.VectorQueryCombination {
+	switch m {
+	case fts.VectorSearchCombinationMethod_And:
+		return vector.VectorQueryCombinationAnd
+	case fts.VectorSearchCombinationMethod_Or:
+		return vector.VectorQueryCombinationOr
+	default:
+		return vector.VectorQueryCombinationNotSet
+	}
+}
+
+// TextSearchQuery
+func TextSearchQuery(q *fts.TextSearchQuery) (search.Query, error) {
+	if q == nil {
+		return nil, errors.New("text search query must not be empty")
+	}
+	switch q.Discriminant() {
+	case fts.TextSearchQueryMatch:
+		payload, _ := q.GetMatch()
+		return MatchQuery(payload), nil
+	case fts.TextSearchQueryMatchPhrase:
+		payload, _ := q.GetMatchPhrase()
+		return MatchPhraseQuery(payload), nil
+	case fts.TextSearchQueryRegexp:
+		payload, _ := q.GetRegexp()
+		return RegexpQuery(payload), nil
+	case fts.TextSearchQueryString:
+		payload, _ := q.GetString()
+		return QueryStringQuery(payload), nil
+	case fts.TextSearchQueryNumericRange:
+		payload, _ := q.GetNumericRange()
+		return NumericRangeQuery(payload)
+	case fts.TextSearchQueryDateRange:
+		payload, _ := q.GetDateRange()
+		return DateRangeQuery(payload)
+	case fts.TextSearchQueryWildcard:
+		payload, _ := q.GetWildcard()
+		return WildcardQuery(payload), nil
+	case fts.TextSearchQueryDocumentId:
+		payload, _ := q.GetDocumentId()
+		return DocumentIdQuery(payload), nil
+	case fts.TextSearchQueryBooleanField:
+		payload, _ := q.GetBooleanField()
+		return BooleanFieldQuery(payload), nil
+	case fts.TextSearchQueryTerm:
+		payload, _ := q.GetTerm()
+		return TermQuery(payload), nil
+	case fts.TextSearchQueryPhrase:
+		payload, _ := q.GetPhrase()
+		return PhraseQuery(payload), nil
+	case fts.TextSearchQueryPrefix:
+		payload, _ := q.GetPrefix()
+		return PrefixQuery(payload), nil
+	case fts.TextSearchQueryMatchAll:
+		return search.NewMatchAllQuery(), nil
+	case fts.TextSearchQueryMatchNone:
+		return search.NewMatchNoneQuery(), nil
+	case fts.TextSearchQueryTermRange:
+		payload, _ := q.GetTermRange()
+		return TermRangeQuery(payload)
+	case fts.TextSearchQueryGeoDistance:
+		payload, _ := q.GetGeoDistance()
+		return GeoDistanceQuery(payload), nil
+	case fts.TextSearchQueryGeoBoundingBox:
+		payload, _ := q.GetGeoBoundingBox()
+		return GeoBoundingBoxQuery(payload), nil
+	case fts.TextSearchQueryGeoPolygon:
+		payload, _ := q.GetGeoPolygon()
+		return GeoPolygonQuery(payload), nil
+	case fts.TextSearchQueryConjunction:
+		payload, _ := q.GetConjunction()
+		return ConjunctionQuery(payload)
+	case fts.TextSearchQueryDisjunction:
+		payload, _ := q.GetDisjunction()
+		return DisjunctionQuery(payload)
+	case fts.TextSearchQueryBoolean:
+		payload, _ := q.GetBoolean()
+		return BooleanQuery(payload)
+	default:
+		return nil, fmt.Errorf("unsupported text search query %s", q)
+	}
+}
+
+// TextSearchClauses
+func TextSearchClauses(clauses []*fts.TextSearchClause) ([]search.Query, error) {
+	queries := make([]search.Query, 0, len(clauses))
+	for _, clause := range clauses {
+		query, err := TextSearchClause(clause)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+// TextSearchClause
+func TextSearchClause(c *fts.TextSearchClause) (search.Query, error) {
+	if c == nil {
+		return nil, errors.New("text search clause must not be empty")
+	}
+	switch c.Discriminant() {
+	case fts.TextSearchClauseMatch:
+		payload, _ := c.GetMatch()
+		return MatchQuery(payload), nil
+	case fts.TextSearchClauseMatchPhrase:
+		payload, _ := c.GetMatchPhrase()
+		return MatchPhraseQuery(payload), nil
+	case fts.TextSearchClauseRegexp:
+		payload, _ := c.GetRegexp()
+		return RegexpQuery(payload), nil
+	case fts.TextSearchClauseString:
+		payload, _ := c.GetString()
+		return QueryStringQuery(payload), nil
+	case fts.TextSearchClauseNumericRange:
+		payload, _ := c.GetNumericRange()
+		return NumericRangeQuery(payload)
+	case fts.TextSearchClauseDateRange:
+		payload, _ := c.GetDateRange()
+		return DateRangeQuery(payload)
+	case fts.TextSearchClauseWildcard:
+		payload, _ := c.GetWildcard()
+		return WildcardQuery(payload), nil
+	case fts.TextSearchClauseDocumentId:
+		payload, _ := c.GetDocumentId()
+		return DocumentIdQuery(payload), nil
+	case fts.TextSearchClauseBooleanField:
+		payload, _ := c.GetBooleanField()
+		return BooleanFieldQuery(payload), nil
+	case fts.TextSearchClauseTerm:
+		payload, _ := c.GetTerm()
+		return TermQuery(payload), nil
+	case fts.TextSearchClausePhrase:
+		payload, _ := c.GetPhrase()
+		return PhraseQuery(payload), nil
+	case fts.TextSearchClausePrefix:
+		payload, _ := c.GetPrefix()
+		return PrefixQuery(payload), nil
+	case fts.TextSearchClauseMatchAll:
+		return search.NewMatchAllQuery(), nil
+	case fts.TextSearchClauseMatchNone:
+		return search.NewMatchNoneQuery(), nil
+	case fts.TextSearchClauseTermRange:
+		payload, _ := c.GetTermRange()
+		return TermRangeQuery(payload)
+	case fts.TextSearchClauseGeoDistance:
+		payload, _ := c.GetGeoDistance()
+		return GeoDistanceQuery(payload), nil
+	case fts.TextSearchClauseGeoBoundingBox:
+		payload, _ := c.GetGeoBoundingBox()
+		return GeoBoundingBoxQuery(payload), nil
+	case fts.TextSearchClauseGeoPolygon:
+		payload, _ := c.GetGeoPolygon()
+		return GeoPolygonQuery(payload), nil
+	default:
+		return nil, fmt.Errorf("unsupported text search clause %s", c)
+	}
+}
+
+// MatchQuery
+func MatchQuery(q *fts.MatchQuery) search.Query {
+	query := search.NewMatchQuery(q.Match)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Analyzer != nil {
+		query.Analyzer(*q.Analyzer)
+	}
+	if q.PrefixLength != nil {
+		query.PrefixLength(*q.PrefixLength)
+	}
+	if q.Fuzziness != nil {
+		query.Fuzziness(*q.Fuzziness)
+	}
+	if q.Operator != nil {
+		switch *q.Operator {
+		case fts.MatchOperator_And:
+			query.Operator(search.MatchOperatorAnd)
+		default:
+			query.Operator(search.MatchOperatorOr)
+		}
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// MatchPhraseQuery
+func MatchPhraseQuery(q *fts.MatchPhraseQuery) search.Query {
+	query := search.NewMatchPhraseQuery(q.Phrase)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Analyzer != nil {
+		query.Analyzer(*q.Analyzer)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// RegexpQuery
+func RegexpQuery(q *fts.RegexpQuery) search.Query {
+	query := search.NewRegexpQuery(q.Regexp)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// QueryStringQuery
+func QueryStringQuery(q *fts.QueryStringQuery) search.Query {
+	query := search.NewQueryStringQuery(q.Query)
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// NumericRangeQuery
+func NumericRangeQuery(q *fts.NumericRangeQuery) (search.Query, error) {
+	if q.Min == nil && q.Max == nil {
+		return nil, errors.New("numeric range query requires min or max")
+	}
+	query := search.NewNumericRangeQuery()
+	if q.Min != nil {
+		query.Min(*q.Min, q.InclusiveMin)
+	}
+	if q.Max != nil {
+		query.Max(*q.Max, q.InclusiveMax)
+	}
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
+
+// DateRangeQuery
+func DateRangeQuery(q *fts.DateRangeQuery) (search.Query, error) {
+	if q.Start == nil && q.End == nil {
+		return nil, errors.New("date range query requires start or end")
+	}
+	query := search.NewDateRangeQuery()
+	if q.Start != nil {
+		query.Start(*q.Start, q.InclusiveStart)
+	}
+	if q.End != nil {
+		query.End(*q.End, q.InclusiveEnd)
+	}
+	if q.DateTimeParser != nil {
+		query.DateTimeParser(*q.DateTimeParser)
+	}
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
+
+// WildcardQuery
+func WildcardQuery(q *fts.WildcardQuery) search.Query {
+	query := search.NewWildcardQuery(q.Wildcard)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// DocumentIdQuery
+func DocumentIdQuery(q *fts.DocumentIdQuery) search.Query {
+	query := search.NewDocIDQuery(q.Ids...)
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// BooleanFieldQuery
+func BooleanFieldQuery(q *fts.BooleanFieldQuery) search.Query {
+	query := search.NewBooleanFieldQuery(q.Value)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// TermQuery
+func TermQuery(q *fts.TermQuery) search.Query {
+	query := search.NewTermQuery(q.Term)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.PrefixLength != nil {
+		query.PrefixLength(*q.PrefixLength)
+	}
+	if q.Fuzziness != nil {
+		query.Fuzziness(*q.Fuzziness)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// PhraseQuery
+func PhraseQuery(q *fts.PhraseQuery) search.Query {
+	query := search.NewPhraseQuery(q.Terms...)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// PrefixQuery
+func PrefixQuery(q *fts.PrefixQuery) search.Query {
+	query := search.NewPrefixQuery(q.Prefix)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// TermRangeQuery
+func TermRangeQuery(q *fts.TermRangeQuery) (search.Query, error) {
+	if q.Min == nil && q.Max == nil {
+		return nil, errors.New("term range query requires min or max")
+	}
+	query := search.NewTermRangeQuery("")
+	if q.Min != nil {
+		query.Min(*q.Min, q.InclusiveMin)
+	}
+	if q.Max != nil {
+		query.Max(*q.Max, q.InclusiveMax)
+	}
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
+
+// GeoDistanceQuery
+func GeoDistanceQuery(q *fts.GeoDistanceQuery) search.Query {
+	query := search.NewGeoDistanceQuery(q.Location.Lon, q.Location.Lat, q.Distance)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// GeoBoundingBoxQuery
+func GeoBoundingBoxQuery(q *fts.GeoBoundingBoxQuery) search.Query {
+	query := search.NewGeoBoundingBoxQuery(q.TopLeft.Lon, q.TopLeft.Lat, q.BottomRight.Lon, q.BottomRight.Lat)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// GeoPolygonQuery
+func GeoPolygonQuery(q *fts.GeoPolygonQuery) search.Query {
+	coordinates := make([]search.Coordinate, 0, len(q.Points))
+	for _, point := range q.Points {
+		coordinates = append(coordinates, search.Coordinate{Lon: point.Lon, Lat: point.Lat})
+	}
+	query := search.NewGeoPolygonQuery(coordinates)
+	if q.Field != nil {
+		query.Field(*q.Field)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query
+}
+
+// ConjunctionQuery
+func ConjunctionQuery(q *fts.ConjunctionQuery) (search.Query, error) {
+	queries, err := TextSearchClauses(q.Queries)
+	if err != nil {
+		return nil, err
+	}
+	query := search.NewConjunctionQuery(queries...)
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
+
+// DisjunctionQuery
+func DisjunctionQuery(q *fts.DisjunctionQuery) (search.Query, error) {
+	queries, err := TextSearchClauses(q.Queries)
+	if err != nil {
+		return nil, err
+	}
+	query := search.NewDisjunctionQuery(queries...)
+	if q.Min != nil {
+		query.Min(*q.Min)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
+
+// BooleanQuery
+func BooleanQuery(q *fts.BooleanQuery) (search.Query, error) {
+	if len(q.Must) == 0 && len(q.Should) == 0 && len(q.MustNot) == 0 {
+		return nil, errors.New("boolean query requires at least one must, should or must-not query")
+	}
+	query := search.NewBooleanQuery()
+	if len(q.Must) > 0 {
+		must, err := TextSearchClauses(q.Must)
+		if err != nil {
+			return nil, err
+		}
+		query.Must(search.NewConjunctionQuery(must...))
+	}
+	if len(q.Should) > 0 {
+		should, err := TextSearchClauses(q.Should)
+		if err != nil {
+			return nil, err
+		}
+		query.Should(search.NewDisjunctionQuery(should...))
+	}
+	if len(q.MustNot) > 0 {
+		mustNot, err := TextSearchClauses(q.MustNot)
+		if err != nil {
+			return nil, err
+		}
+		query.MustNot(search.NewDisjunctionQuery(mustNot...))
+	}
+	if q.ShouldMin != nil {
+		query.ShouldMin(*q.ShouldMin)
+	}
+	if q.Boost != nil {
+		query.Boost(*q.Boost)
+	}
+	return query, nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/fts"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/search_index_management"
+)
+
+func TestVectorSearchRequest(t *testing.T) {
+	category := "category"
+	prefilter := fts.NewTextSearchQueryTerm(&fts.TermQuery{Term: "shoes", Field: &category})
+
+	tests := []struct {
+		name         string
+		query        *fts.VectorSearchQuery
+		expectRaw    bool
+		expectFilter bool
+		expectError  bool
+	}{
+		{
+			name: "knn",
+			query: &fts.VectorSearchQuery{
+				Queries: []*fts.VectorQuery{{Field: "embedding", Vector: []float32{0.1, 0.2}}},
+			},
+		},
+		{
+			name: "prefiltered knn",
+			query: &fts.VectorSearchQuery{
+				Queries:     []*fts.VectorQuery{{Field: "embedding", Vector: []float32{0.1, 0.2}, Prefilter: prefilter}},
+				Combination: fts.VectorSearchCombinationMethod_And,
+			},
+			expectRaw:    true,
+			expectFilter: true,
+		},
+		{
+			name:        "no queries",
+			query:       &fts.VectorSearchQuery{},
+			expectError: true,
+		},
+		{
+			name: "empty vector",
+			query: &fts.VectorSearchQuery{
+				Queries: []*fts.VectorQuery{{Field: "embedding"}},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range tests {
+		request, raw, err := VectorSearchRequest(test.query)
+		if test.expectError {
+			if err == nil {
+				t.Errorf("expected error for %s, got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("did not expect error for %s, got %v", test.name, err)
+			continue
+		}
+		if !test.expectRaw {
+			if raw != nil || request.VectorSearch == nil {
+				t.Errorf("expected %s to use a vector search request", test.name)
+			}
+			continue
+		}
+		if request.VectorSearch != nil || raw["knn_operator"] != "and" {
+			t.Errorf("expected %s to use raw knn parameters, got %v", test.name, raw)
+		}
+		encoded, err := json.Marshal(raw["knn"])
+		if err != nil {
+			t.Fatalf("failed to encode knn for %s: %v", test.name, err)
+		}
+		var knn []map[string]json.RawMessage
+		if err := json.Unmarshal(encoded, &knn); err != nil {
+			t.Fatalf("failed to decode knn for %s: %v", test.name, err)
+		}
+		if _, ok := knn[0]["filter"]; ok != test.expectFilter {
+			t.Errorf("expected filter in knn for %s: %s", test.name, encoded)
+		}
+		if string(knn[0]["k"]) != "3" {
+			t.Errorf("expected default k of 3 for %s, got %s", test.name, knn[0]["k"])
+		}
+	}
+}
+
+func TestSearchIndexDefinition(t *testing.T) {
+	params := `{"mapping":{"default_mapping":{"enabled":true}}}`
+	index := &search_index_management.SearchIndex{
+		Name:       "hotels",
+		IndexType:  "fulltext-index",
+		SourceName: "travel-sample",
+		SourceType: "gocbcore",
+		Params:     &params,
+	}
+
+	definition, err := SearchIndexDefinition(index)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if definition.SourceParams != nil || definition.PlanParams != nil {
+		t.Errorf("expected unset params to stay unset, got %v and %v", definition.SourceParams, definition.PlanParams)
+	}
+	// Converting back must give the same definition, so that indexes can be read, modified and upserted
+	if actual := SearchIndex(definition); *actual.Params != params || actual.Name != index.Name || actual.SourceName != index.SourceName {
+		t.Errorf("expected %+v after converting back, got %+v", index, actual)
+	}
+
+	invalid := `["not", "an", "object"]`
+	if _, err := SearchIndexDefinition(&search_index_management.SearchIndex{Name: "hotels", PlanParams: &invalid}); err == nil {
+		t.Error("expected an error for plan params that are not a JSON object")
+	}
+}
@@ -5,6 +5,8 @@ go 1.22.3
 require (
 	github.com/couchbase/gocb-opentelemetry v0.1.2-0.20240814081329-f68bd3eca445
 	github.com/couchbase/gocb/v2 v2.9.2-0.20240814074849-fcf55fc858b3
+	github.com/couchbase/gocbcore/v10 v10.5.2-0.20240730072846-40aebed77ad1
+	github.com/google/uuid v1.6.0
 	go.opentelemetry.io/otel/exporters/otlp/otlptrace v1.28.0
 	go.wasmcloud.dev/provider v0.0.4
 	wrpc.io/go v0.1.0
@@ -27,14 +29,12 @@ require (
 )
 
 require (
-	github.com/couchbase/gocbcore/v10 v10.5.2-0.20240730072846-40aebed77ad1 // indirect
 	github.com/couchbase/gocbcoreps v0.1.3 // indirect
 	github.com/couchbase/goprotostellar v1.0.2 // indirect
 	github.com/couchbaselabs/gocbconnstr/v2 v2.0.0-20240607131231-fb385523de28 // indirect
 	github.com/go-logr/logr v1.4.2 // indirect
 	github.com/go-logr/stdr v1.2.2 // indirect
 	github.com/golang/snappy v0.0.4 // indirect
-	github.com/google/uuid v1.6.0 // indirect
 	github.com/grpc-ecosystem/go-grpc-middleware v1.4.0 // indirect
 	github.com/klauspost/compress v1.17.8 // indirect
 	github.com/nats-io/nats.go v1.37.0 // indirect
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/atomics"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/batch"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/store"
+)
+
+// Maximum number of keys returned by a single list-keys call
+const listKeysPageSize = 1000
+
+var errKeyListingNotFound = errors.New("list-keys cursor does not exist")
+
+// Serves the wrpc:keyvalue interfaces on the links (and connections) of the provider
+//
+// The keyvalue interfaces share function names with the document interface (ex. get, exists),
+// so they are implemented on a separate type wrapping the provider handler.
+type KeyvalueHandler struct {
+	*Handler
+}
+
+var errNoSuchKeyvalueStore = errors.New("keyvalue store does not exist")
+
+// Identifier of the store on the collection configured on the link (as is an empty identifier)
+const defaultKeyvalueStore = "default"
+
+// newKeyListings returns the registry of list-keys scans, whose handles are the numeric cursors handed out to components
+func newKeyListings() *scanCursors {
+	var lastCursor atomic.Uint64
+	return &scanCursors{
+		cursors:  make(map[string]*scanCursor),
+		notFound: errKeyListingNotFound,
+		newHandle: func() string {
+			return strconv.FormatUint(lastCursor.Add(1), 10)
+		},
+	}
+}
+
+// Get implements store.Handler.
+func (h *KeyvalueHandler) Get(ctx context.Context, bucket string, key string) (*wrpc.Result[[]uint8, store.Error], error) {
+	_, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[[]uint8](*KeyvalueError(err)), nil
+	}
+	value, err := keyvalueGet(collection, key)
+	if err != nil {
+		h.Logger.Error("Error getting value", "key", key, "error", err)
+		return wrpc.Err[[]uint8](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[store.Error](value), nil
+}
+
+// Set implements store.Handler.
+func (h *KeyvalueHandler) Set(ctx context.Context, bucket string, key string, value []uint8) (*wrpc.Result[struct{}, store.Error], error) {
+	_, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	if err := keyvalueSet(collection, key, value); err != nil {
+		h.Logger.Error("Error setting value", "key", key, "error", err)
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[store.Error](struct{}{}), nil
+}
+
+// Delete implements store.Handler.
+func (h *KeyvalueHandler) Delete(ctx context.Context, bucket string, key string) (*wrpc.Result[struct{}, store.Error], error) {
+	_, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	if err := keyvalueDelete(collection, key); err != nil {
+		h.Logger.Error("Error deleting value", "key", key, "error", err)
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[store.Error](struct{}{}), nil
+}
+
+// Exists implements store.Handler.
+func (h *KeyvalueHandler) Exists(ctx context.Context, bucket string, key string) (*wrpc.Result[bool, store.Error], error) {
+	_, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[bool](*KeyvalueError(err)), nil
+	}
+	result, err := collection.Exists(key, nil)
+	if err != nil {
+		h.Logger.Error("Error checking existence of value", "key", key, "error", err)
+		return wrpc.Err[bool](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[store.Error](result.Exists()), nil
+}
+
+// ListKeys implements store.Handler.
+func (h *KeyvalueHandler) ListKeys(ctx context.Context, bucket string, cursor *uint64) (*wrpc.Result[store.KeyResponse, store.Error], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connection, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[store.KeyResponse](*KeyvalueError(err)), nil
+	}
+
+	// Keys are listed with a single ids-only scan, kept open between pages so that it can be continued
+	var handle string
+	if cursor == nil {
+		result, err := collection.Scan(gocb.RangeScan{}, &gocb.ScanOptions{IDsOnly: true})
+		if err != nil {
+			h.Logger.Error("Error starting key listing", "error", err)
+			return wrpc.Err[store.KeyResponse](*KeyvalueError(err)), nil
+		}
+		handle = h.keyListings.open(newScanCursor(sourceId, linkName, result, connection.CursorIdleTimeout))
+	} else {
+		handle = strconv.FormatUint(*cursor, 10)
+	}
+	listing, err := h.keyListings.get(sourceId, linkName, handle)
+	if err != nil {
+		return wrpc.Err[store.KeyResponse](*store.NewErrorOther(err.Error())), nil
+	}
+
+	items, done, err := listing.next(listKeysPageSize)
+	if err != nil {
+		h.Logger.Error("Error listing keys", "error", err)
+		_ = h.keyListings.close(sourceId, linkName, handle)
+		return wrpc.Err[store.KeyResponse](*KeyvalueError(err)), nil
+	}
+	response := store.KeyResponse{Keys: make([]string, 0, len(items))}
+	for _, item := range items {
+		response.Keys = append(response.Keys, item.ID())
+	}
+	if done {
+		_ = h.keyListings.close(sourceId, linkName, handle)
+	} else {
+		next, _ := strconv.ParseUint(handle, 10, 64)
+		response.Cursor = &next
+	}
+	return wrpc.Ok[store.Error](response), nil
+}
+
+// Increment implements atomics.Handler.
+func (h *KeyvalueHandler) Increment(ctx context.Context, bucket string, key string, delta uint64) (*wrpc.Result[uint64, atomics.Error], error) {
+	_, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[uint64](*KeyvalueError(err)), nil
+	}
+	// Missing keys are created with the delta as their value, which the cluster takes as a signed integer
+	if delta > math.MaxInt64 {
+		return wrpc.Err[uint64](*store.NewErrorOther(fmt.Sprintf("delta %d is above %d", delta, int64(math.MaxInt64)))), nil
+	}
+	result, err := collection.Binary().Increment(key, &gocb.IncrementOptions{
+		Initial: int64(delta),
+		Delta:   delta,
+	})
+	if err != nil {
+		h.Logger.Error("Error incrementing value", "key", key, "error", err)
+		return wrpc.Err[uint64](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[atomics.Error](result.Content()), nil
+}
+
+// GetMany implements batch.Handler.
+func (h *KeyvalueHandler) GetMany(ctx context.Context, bucket string, keys []string) (*wrpc.Result[[]*wrpc.Tuple2[string, []uint8], batch.Error], error) {
+	connection, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[[]*wrpc.Tuple2[string, []uint8]](*KeyvalueError(err)), nil
+	}
+	// Missing keys are reported as `none`, in the position of the key
+	values := make([]*wrpc.Tuple2[string, []uint8], len(keys))
+	errs, err := bulk(ctx, len(keys), connection.bulkSlots, func(idx int) error {
+		value, err := keyvalueGet(collection, keys[idx])
+		if err != nil {
+			h.Logger.Error("Error getting value", "key", keys[idx], "error", err)
+			return err
+		}
+		if value != nil {
+			values[idx] = &wrpc.Tuple2[string, []uint8]{V0: keys[idx], V1: value}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return wrpc.Err[[]*wrpc.Tuple2[string, []uint8]](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[batch.Error](values), nil
+}
+
+// SetMany implements batch.Handler.
+func (h *KeyvalueHandler) SetMany(ctx context.Context, bucket string, keyValues []*wrpc.Tuple2[string, []uint8]) (*wrpc.Result[struct{}, batch.Error], error) {
+	connection, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	// Values that were set before an error occurred are not rolled back
+	errs, err := bulk(ctx, len(keyValues), connection.bulkSlots, func(idx int) error {
+		key, value := keyValues[idx].V0, keyValues[idx].V1
+		err := keyvalueSet(collection, key, value)
+		if err != nil {
+			h.Logger.Error("Error setting value", "key", key, "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[batch.Error](struct{}{}), nil
+}
+
+// DeleteMany implements batch.Handler.
+func (h *KeyvalueHandler) DeleteMany(ctx context.Context, bucket string, keys []string) (*wrpc.Result[struct{}, batch.Error], error) {
+	connection, collection, err := h.getStoreFromContext(ctx, bucket)
+	if err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	// Values that were deleted before an error occurred are not restored
+	errs, err := bulk(ctx, len(keys), connection.bulkSlots, func(idx int) error {
+		err := keyvalueDelete(collection, keys[idx])
+		if err != nil {
+			h.Logger.Error("Error deleting value", "key", keys[idx], "error", err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := errors.Join(errs...); err != nil {
+		return wrpc.Err[struct{}](*KeyvalueError(err)), nil
+	}
+	return wrpc.Ok[batch.Error](struct{}{}), nil
+}
+
+// getStoreFromContext returns the connection of the link the invocation was made on, along with the collection of a store
+func (h *KeyvalueHandler) getStoreFromContext(ctx context.Context, bucket string) (*CouchbaseConnection, *gocb.Collection, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, nil, err
+	}
+	collection, err := connection.keyvalueStore(bucket)
+	if err != nil {
+		h.Logger.Warn("Received request for unknown keyvalue store", "bucket", bucket)
+		return nil, nil, err
+	}
+	return connection, collection, nil
+}
+
+// keyvalueStore resolves the identifier of a keyvalue store to a collection, which is either
+// aliased in the link config, the collection configured on the link for the default store,
+// or a scope.collection keyspace of the bucket of the link
+func (c *CouchbaseConnection) keyvalueStore(identifier string) (*gocb.Collection, error) {
+	keyspace, ok := c.KeyvalueStores[identifier]
+	if !ok {
+		if identifier == "" || identifier == defaultKeyvalueStore {
+			return c.Collection, nil
+		}
+		if keyspace, ok = parseKeyvalueKeyspace(identifier); !ok {
+			return nil, errNoSuchKeyvalueStore
+		}
+	}
+	return c.Collection.Bucket().Scope(keyspace.ScopeName).Collection(keyspace.CollectionName), nil
+}
+
+// keyvalueGet returns the value of a key, which is nil if the key does not exist
+func keyvalueGet(collection *gocb.Collection, key string) ([]uint8, error) {
+	result, err := collection.Get(key, &gocb.GetOptions{Transcoder: gocb.NewRawJSONTranscoder()})
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var value []uint8
+	if err := result.Content(&value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// keyvalueSet stores the value of a key as-is
+func keyvalueSet(collection *gocb.Collection, key string, value []uint8) error {
+	_, err := collection.Upsert(key, value, &gocb.UpsertOptions{Transcoder: gocb.NewRawJSONTranscoder()})
+	return err
+}
+
+// keyvalueDelete removes a key, doing nothing if the key does not exist
+func keyvalueDelete(collection *gocb.Collection, key string) error {
+	_, err := collection.Remove(key, nil)
+	if errors.Is(err, gocb.ErrDocumentNotFound) {
+		return nil
+	}
+	return err
+}
+
+// KeyvalueError maps errors of keyvalue operations to their store error
+func KeyvalueError(err error) *store.Error {
+	switch {
+	case errors.Is(err, errUnlinkedSource), errors.Is(err, errNoSuchKeyvalueStore),
+		errors.Is(err, gocb.ErrScopeNotFound), errors.Is(err, gocb.ErrCollectionNotFound):
+		return store.NewErrorNoSuchStore()
+	case errors.Is(err, gocb.ErrAuthenticationFailure):
+		return store.NewErrorAccessDenied()
+	default:
+		return store.NewErrorOther(err.Error())
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/couchbase/gocb/v2"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wrpc/keyvalue/store"
+)
+
+func TestKeyListingCursors(t *testing.T) {
+	listings := newKeyListings()
+	first := listings.open(newScanCursor("component", "default", nil, 0))
+	second := listings.open(newScanCursor("component", "default", nil, 0))
+	if first != "1" || second != "2" {
+		t.Fatalf("expected numeric cursors 1 and 2, got %s and %s", first, second)
+	}
+	if _, err := listings.get("other", "default", first); !errors.Is(err, errKeyListingNotFound) {
+		t.Errorf("expected listing to only be continued on its own link, got %v", err)
+	}
+}
+
+func TestKeyvalueError(t *testing.T) {
+	if actual := KeyvalueError(gocb.ErrAuthenticationFailure).Discriminant(); actual != store.ErrorAccessDenied {
+		t.Errorf("expected authentication failures to map to access-denied, got %v", actual)
+	}
+	if actual := KeyvalueError(gocb.ErrTimeout).Discriminant(); actual != store.ErrorOther {
+		t.Errorf("expected timeouts to map to other, got %v", actual)
+	}
+}
+
+func TestUnknownKeyvalueStore(t *testing.T) {
+	connection := &CouchbaseConnection{
+		KeyvalueStores: map[string]KeyvalueKeyspace{"sessions": {ScopeName: "app", CollectionName: "sessions"}},
+	}
+	for _, identifier := range []string{"cache", "app", "app.sessions.extra"} {
+		if _, err := connection.keyvalueStore(identifier); !errors.Is(err, errNoSuchKeyvalueStore) {
+			t.Errorf("expected store '%s' to not exist, got %v", identifier, err)
+		}
+	}
+	if actual := KeyvalueError(gocb.ErrCollectionNotFound).Discriminant(); actual != store.ErrorNoSuchStore {
+		t.Errorf("expected missing collections to map to no-such-store, got %v", actual)
+	}
+}
+
+func TestKeyvalueLinkErrors(t *testing.T) {
+	unlinked := fmt.Errorf("%w %s with link name %s", errUnlinkedSource, "component", "default")
+	if actual := KeyvalueError(unlinked).Discriminant(); actual != store.ErrorNoSuchStore {
+		t.Errorf("expected unlinked sources to map to no-such-store, got %v", actual)
+	}
+	notReady := fmt.Errorf("%w (%s)", errLinkNotReady, linkConnecting)
+	if actual := KeyvalueError(notReady).Discriminant(); actual != store.ErrorOther {
+		t.Errorf("expected links that are not ready to map to other, got %v", actual)
+	}
+}
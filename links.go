@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+const (
+	// Bounds of the delay between attempts to connect a link
+	linkConnectMinBackoff = time.Second
+	linkConnectMaxBackoff = time.Minute
+	// How often the cluster of a connected link is pinged, and how long it may take to respond
+	linkHealthCheckInterval = 30 * time.Second
+	linkHealthCheckTimeout  = 5 * time.Second
+	// How long the connection of a link that was replaced is kept open, so that requests that started
+	// on it complete. This is longer than the default timeout of any cluster operation
+	linkDrainTimeout = 2 * time.Minute
+)
+
+var (
+	errLinkNotReady   = errors.New("link is not ready")
+	errLinkDeleted    = errors.New("link was deleted")
+	errUnlinkedSource = errors.New("received request from unlinked source")
+)
+
+// Connection state of a link
+type linkState uint8
+
+const (
+	// The cluster has not been connected to yet
+	linkConnecting linkState = iota
+	// The cluster is connected and responding
+	linkReady
+	// The cluster was connected but stopped responding, requests are still attempted while it recovers
+	linkDegraded
+	// Connecting failed in a way that is unlikely to resolve by itself, such as invalid credentials.
+	// Connecting is still retried, but requests fail right away
+	linkFailed
+)
+
+func (s linkState) String() string {
+	switch s {
+	case linkConnecting:
+		return "connecting"
+	case linkReady:
+		return "ready"
+	case linkDegraded:
+		return "degraded"
+	case linkFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// The connection of a link, established in the background and monitored once connected
+type linkConnection struct {
+	sourceId       string
+	linkName       string
+	connectionArgs CouchbaseConnectionArgs
+	connect        func(connectionArgs CouchbaseConnectionArgs) (*CouchbaseConnection, error)
+	// Reports whether the cluster of a connection is responding
+	ping   func(connection *CouchbaseConnection) error
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	state      linkState
+	connection *CouchbaseConnection
+	// Why the link is not ready, if it is not
+	err     error
+	stopped bool
+	// Closed and replaced whenever the state changes, waking up requests waiting for the link
+	changed chan struct{}
+	cancel  context.CancelFunc
+	// The link this link replaced, which serves requests until this link is ready
+	previous     *linkConnection
+	drainTimeout time.Duration
+}
+
+func newLinkConnection(
+	sourceId string,
+	linkName string,
+	connectionArgs CouchbaseConnectionArgs,
+	connect func(CouchbaseConnectionArgs) (*CouchbaseConnection, error),
+	ping func(*CouchbaseConnection) error,
+	logger *slog.Logger,
+) *linkConnection {
+	return &linkConnection{
+		sourceId:       sourceId,
+		linkName:       linkName,
+		connectionArgs: connectionArgs,
+		connect:        connect,
+		ping:           ping,
+		logger:         logger.With("sourceId", sourceId, "link", linkName),
+		changed:        make(chan struct{}),
+		drainTimeout:   linkDrainTimeout,
+	}
+}
+
+// start connects the link in the background
+func (l *linkConnection) start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		// The link was deleted before it started
+		cancel()
+		return
+	}
+	l.cancel = cancel
+	go l.run(ctx)
+}
+
+// stop stops connecting or monitoring the link, and closes its connection.
+// Requests still waiting for the link fail
+func (l *linkConnection) stop() error {
+	l.mu.Lock()
+	connection, previous, cancel := l.connection, l.previous, l.cancel
+	l.stopped = true
+	l.connection = nil
+	l.previous = nil
+	l.err = errLinkDeleted
+	l.notify()
+	l.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+	var errs []error
+	if previous != nil {
+		errs = append(errs, previous.stop())
+	}
+	if connection != nil {
+		errs = append(errs, connection.close())
+	}
+	return errors.Join(errs...)
+}
+
+// retire stops the link once the requests that are using it had time to complete
+func (l *linkConnection) retire() {
+	time.AfterFunc(l.drainTimeout, func() {
+		if err := l.stop(); err != nil {
+			l.logger.Warn("Error closing replaced cluster connection", "error", err)
+		}
+	})
+}
+
+// current returns the connection requests are served from right away: the connection of the link,
+// or the connection of the link it replaced until it is ready
+func (l *linkConnection) current() *CouchbaseConnection {
+	l.mu.Lock()
+	connection, previous := l.connection, l.previous
+	l.mu.Unlock()
+	if connection == nil && previous != nil {
+		return previous.current()
+	}
+	return connection
+}
+
+// await returns the connection of the link, waiting up to timeout for the link to connect
+func (l *linkConnection) await(ctx context.Context, timeout time.Duration) (*CouchbaseConnection, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	for {
+		connection := l.current()
+		l.mu.Lock()
+		state, err, changed, stopped := l.state, l.err, l.changed, l.stopped
+		l.mu.Unlock()
+
+		switch {
+		case connection != nil:
+			// Requests on a degraded link are attempted, as the cluster may recover before they time out
+			return connection, nil
+		case stopped || state == linkFailed:
+			return nil, fmt.Errorf("%w (%s): %w", errLinkNotReady, state, err)
+		}
+
+		select {
+		case <-changed:
+		case <-timer.C:
+			if err != nil {
+				return nil, fmt.Errorf("%w (%s): %w", errLinkNotReady, state, err)
+			}
+			return nil, fmt.Errorf("%w (%s)", errLinkNotReady, state)
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w (%s): %w", errLinkNotReady, state, ctx.Err())
+		}
+	}
+}
+
+// status returns the state of the link, and why it is not ready if it is not
+func (l *linkConnection) status() (linkState, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state, l.err
+}
+
+func (l *linkConnection) run(ctx context.Context) {
+	backoff := newBackoff(linkConnectMinBackoff, linkConnectMaxBackoff)
+	var connection *CouchbaseConnection
+	for connection == nil {
+		var err error
+		connection, err = l.connect(l.connectionArgs)
+		if err != nil {
+			state := linkConnecting
+			if isPermanentConnectError(err) {
+				state = linkFailed
+			}
+			l.logger.Warn("Error connecting link, retrying", "state", state, "error", err)
+			l.setState(state, nil, err)
+			if !backoff.wait(ctx) {
+				return
+			}
+		}
+	}
+	if !l.setState(linkReady, connection, nil) {
+		// The link was deleted while it was connecting
+		_ = connection.close()
+		return
+	}
+	l.logger.Info("Link connected")
+
+	// Requests are now served from this link, the link it replaced can be closed once they completed
+	l.mu.Lock()
+	previous := l.previous
+	l.previous = nil
+	l.mu.Unlock()
+	if previous != nil {
+		previous.retire()
+	}
+
+	ticker := time.NewTicker(linkHealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		state := linkReady
+		err := l.ping(connection)
+		if err != nil {
+			state = linkDegraded
+		}
+		if previous, _ := l.status(); previous != state {
+			l.logger.Warn("Link state changed", "from", previous, "to", state, "error", err)
+		}
+		l.setState(state, connection, err)
+	}
+}
+
+// setState updates the state of the link, returning false if the link was stopped
+func (l *linkConnection) setState(state linkState, connection *CouchbaseConnection, err error) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.stopped {
+		return false
+	}
+	changed := l.state != state || l.connection != connection
+	l.state, l.connection, l.err = state, connection, err
+	if changed {
+		l.notify()
+	}
+	return true
+}
+
+// notify wakes up the requests waiting for the link, it must be called with the lock held
+func (l *linkConnection) notify() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
+
+// Registry of the connections of the links to this provider
+type linkRegistry struct {
+	mu sync.RWMutex
+	// sourceID -> linkName -> link connection
+	links map[string]map[string]*linkConnection
+}
+
+func newLinkRegistry() *linkRegistry {
+	return &linkRegistry{links: make(map[string]map[string]*linkConnection)}
+}
+
+// get returns the connection of a link
+func (r *linkRegistry) get(sourceId, linkName string) (*linkConnection, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	link, exists := r.links[sourceId][linkName]
+	return link, exists
+}
+
+// put registers the connection of a link, replacing the previous connection of the link if the link
+// config changed, and returns whether it did. The replaced connection serves requests until the new one is ready
+func (r *linkRegistry) put(link *linkConnection) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.links[link.sourceId] == nil {
+		r.links[link.sourceId] = make(map[string]*linkConnection)
+	}
+	previous, exists := r.links[link.sourceId][link.linkName]
+	if exists && reflect.DeepEqual(previous.connectionArgs, link.connectionArgs) {
+		return false
+	}
+	link.previous = previous
+	r.links[link.sourceId][link.linkName] = link
+	return true
+}
+
+// remove unregisters the connection of a link
+func (r *linkRegistry) remove(sourceId, linkName string) (*linkConnection, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	link, exists := r.links[sourceId][linkName]
+	if !exists {
+		return nil, false
+	}
+	delete(r.links[sourceId], linkName)
+	if len(r.links[sourceId]) == 0 {
+		delete(r.links, sourceId)
+	}
+	return link, true
+}
+
+// removeAll unregisters the connections of all links
+func (r *linkRegistry) removeAll() []*linkConnection {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var links []*linkConnection
+	for _, connections := range r.links {
+		for _, link := range connections {
+			links = append(links, link)
+		}
+	}
+	clear(r.links)
+	return links
+}
+
+// Errors that retrying the connection is unlikely to resolve without a change to the cluster or config
+func isPermanentConnectError(err error) bool {
+	return errors.Is(err, gocb.ErrAuthenticationFailure) || errors.Is(err, gocb.ErrBucketNotFound)
+}
+
+// Ping the key-value service of the bucket of a connection
+func pingCouchbase(connection *CouchbaseConnection) error {
+	result, err := connection.Collection.Bucket().Ping(&gocb.PingOptions{
+		ServiceTypes: []gocb.ServiceType{gocb.ServiceTypeKeyValue},
+		Timeout:      linkHealthCheckTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	for _, endpoints := range result.Services {
+		for _, endpoint := range endpoints {
+			if endpoint.State != gocb.PingStateOk {
+				return fmt.Errorf("endpoint %s did not respond: %s", endpoint.Remote, endpoint.Error)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+)
+
+// connectAfter returns a connect function failing with the given errors before connecting
+func connectAfter(errs ...error) func(CouchbaseConnectionArgs) (*CouchbaseConnection, error) {
+	attempts := make(chan error, len(errs))
+	for _, err := range errs {
+		attempts <- err
+	}
+	return func(CouchbaseConnectionArgs) (*CouchbaseConnection, error) {
+		select {
+		case err := <-attempts:
+			return nil, err
+		default:
+			return &CouchbaseConnection{}, nil
+		}
+	}
+}
+
+func newTestLinkConnection(connect func(CouchbaseConnectionArgs) (*CouchbaseConnection, error)) *linkConnection {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	ping := func(*CouchbaseConnection) error { return nil }
+	return newLinkConnection("component", "default", CouchbaseConnectionArgs{}, connect, ping, logger)
+}
+
+func TestLinkConnectionRetriesConnecting(t *testing.T) {
+	link := newTestLinkConnection(connectAfter(gocb.ErrAuthenticationFailure))
+	link.start()
+	defer link.stop()
+
+	// Wait for the first attempt to fail
+	for state, _ := link.status(); state != linkFailed; state, _ = link.status() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := link.await(context.Background(), time.Minute); !errors.Is(err, errLinkNotReady) || !errors.Is(err, gocb.ErrAuthenticationFailure) {
+		t.Fatalf("expected requests on a failed link to fail right away, got %v", err)
+	}
+
+	connection, err := link.await(context.Background(), time.Minute)
+	for errors.Is(err, errLinkNotReady) {
+		time.Sleep(10 * time.Millisecond)
+		connection, err = link.await(context.Background(), time.Minute)
+	}
+	if err != nil || connection == nil {
+		t.Fatalf("expected link to be connected once retried, got %v", err)
+	}
+	if state, _ := link.status(); state != linkReady {
+		t.Fatalf("expected link to be ready, got %s", state)
+	}
+}
+
+func TestLinkConnectionNotReady(t *testing.T) {
+	link := newTestLinkConnection(connectAfter(gocb.ErrTimeout))
+	link.start()
+
+	if _, err := link.await(context.Background(), 0); !errors.Is(err, errLinkNotReady) {
+		t.Fatalf("expected requests on a connecting link to fail once the ready timeout elapsed, got %v", err)
+	}
+
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := link.await(context.Background(), time.Minute)
+		waiting <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	_ = link.stop()
+	select {
+	case err := <-waiting:
+		if !errors.Is(err, errLinkDeleted) {
+			t.Fatalf("expected waiting requests to fail once the link was deleted, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected waiting requests to fail once the link was deleted")
+	}
+}
+
+func TestLinkRegistryReplacesChangedLinks(t *testing.T) {
+	registry := newLinkRegistry()
+	link := newTestLinkConnection(connectAfter())
+	link.connectionArgs.BucketName = "orders"
+	if !registry.put(link) {
+		t.Fatal("expected new link to be registered")
+	}
+
+	unchanged := newTestLinkConnection(connectAfter())
+	unchanged.connectionArgs.BucketName = "orders"
+	if registry.put(unchanged) {
+		t.Fatal("expected link put again with the same config to keep its connection")
+	}
+
+	changed := newTestLinkConnection(connectAfter())
+	changed.connectionArgs.BucketName = "invoices"
+	if !registry.put(changed) {
+		t.Fatal("expected link put again with a different config to be replaced")
+	}
+	if current, _ := registry.get("component", "default"); current != changed || changed.previous != link {
+		t.Fatal("expected replaced link to serve requests until the new link is ready")
+	}
+
+	if _, exists := registry.remove("component", "default"); !exists {
+		t.Fatal("expected link to be removed")
+	}
+	if _, exists := registry.get("component", "default"); exists {
+		t.Fatal("expected removed link to not be found")
+	}
+}
+
+func TestLinkConnectionSwapsOnceReady(t *testing.T) {
+	registry := newLinkRegistry()
+	old := newTestLinkConnection(connectAfter())
+	old.drainTimeout = 10 * time.Millisecond
+	registry.put(old)
+	old.start()
+	oldConnection, err := old.await(context.Background(), time.Minute)
+	if err != nil {
+		t.Fatalf("unexpected error connecting link: %v", err)
+	}
+
+	// The new connection is only established once allowed to
+	connect := make(chan struct{})
+	replacement := newTestLinkConnection(func(CouchbaseConnectionArgs) (*CouchbaseConnection, error) {
+		<-connect
+		return &CouchbaseConnection{}, nil
+	})
+	replacement.connectionArgs.BucketName = "invoices"
+	registry.put(replacement)
+	replacement.start()
+	defer replacement.stop()
+
+	// Requests are served concurrently while the link is swapped
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				link, _ := registry.get("component", "default")
+				if _, err := link.await(context.Background(), time.Minute); err != nil {
+					t.Errorf("expected requests to be served while the link is swapped, got %v", err)
+					return
+				}
+			}
+		}()
+	}
+
+	link, _ := registry.get("component", "default")
+	if connection, _ := link.await(context.Background(), 0); connection != oldConnection {
+		t.Fatal("expected requests to be served from the replaced link while the new one connects")
+	}
+	close(connect)
+	wg.Wait()
+
+	// The replaced link is closed once drained
+	for _, err := old.status(); !errors.Is(err, errLinkDeleted); _, err = old.status() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	connection, err := link.await(context.Background(), time.Minute)
+	if err != nil || connection == oldConnection {
+		t.Fatalf("expected requests to be served from the new link once ready, got %v", err)
+	}
+}
@@ -11,7 +11,6 @@ import (
 	"syscall"
 
 	wrpc "github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings"
-	"github.com/couchbase/gocb/v2"
 	"go.wasmcloud.dev/provider"
 )
 
@@ -38,12 +37,20 @@ func run() error {
 
 	// Initialize the provider with callbacks to track linked components
 	providerHandler := Handler{
-		clusterConnections: make(map[string]map[string]*gocb.Collection),
+		links:               newLinkRegistry(),
+		clusters:            newClusterPool(),
+		queryCursors:        newQueryCursors(),
+		scanCursors:         newScanCursors(),
+		keyListings:         newKeyListings(),
+		transactions:        newTxRegistry(),
+		changeSubscriptions: newSubscriptionRegistry(),
 	}
 
 	p, err := provider.New(
 		provider.TargetLinkPut(providerHandler.handleNewTargetLink),
 		provider.TargetLinkDel(providerHandler.handleDelTargetLink),
+		provider.SourceLinkPut(providerHandler.handleNewSourceLink),
+		provider.SourceLinkDel(providerHandler.handleDelSourceLink),
 		provider.HealthCheck(providerHandler.handleHealthCheck),
 		provider.Shutdown(providerHandler.handleShutdown),
 	)
@@ -59,12 +66,30 @@ func run() error {
 	signalCh := make(chan os.Signal, 1)
 
 	// Handle RPC operations
-	stopFunc, err := wrpc.Serve(p.RPCClient, &providerHandler)
+	keyvalueHandler := KeyvalueHandler{&providerHandler}
+	stopFunc, err := wrpc.Serve(p.RPCClient, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &providerHandler, &keyvalueHandler, &keyvalueHandler, &keyvalueHandler, &providerHandler)
 	if err != nil {
 		p.Shutdown()
 		return err
 	}
 
+	// Close query cursors abandoned by components
+	go providerHandler.queryCursors.reap(ctx, func(count int) {
+		providerHandler.Logger.Info("Closed idle query cursors", "count", count)
+	})
+	// Close scan cursors abandoned by components
+	go providerHandler.scanCursors.reap(ctx, func(count int) {
+		providerHandler.Logger.Info("Closed idle scan cursors", "count", count)
+	})
+	// Close key listings abandoned by components
+	go providerHandler.keyListings.reap(ctx, func(count int) {
+		providerHandler.Logger.Info("Closed idle key listings", "count", count)
+	})
+	// Roll back transactions abandoned by components
+	go providerHandler.transactions.reap(ctx, func(count int) {
+		providerHandler.Logger.Info("Rolled back idle transactions", "count", count)
+	})
+
 	// Handle control interface operations
 	go func() {
 		err := p.Start()
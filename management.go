@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	wrpc "wrpc.io/go"
+)
+
+var errManagementNotAllowed = errors.New("management operations are not allowed on this link")
+
+// Helper function to get the cluster connection of the link the invocation was made on,
+// only if the link allows management operations
+func (h *Handler) getManagementConnectionFromContext(ctx context.Context) (*CouchbaseConnection, error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	if !connection.AllowManagement {
+		h.Logger.Warn("Received management request on a link that does not allow it")
+		return nil, errManagementNotAllowed
+	}
+	return connection, nil
+}
+
+// managementLinkError reports links that do not allow management with the not-allowed error of a management interface
+func managementLinkError[T, E any](err error, notAllowed *E) (*wrpc.Result[T, E], error) {
+	if errors.Is(err, errManagementNotAllowed) {
+		return wrpc.Err[T](*notAllowed), nil
+	}
+	return nil, err
+}
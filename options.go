@@ -1,11 +1,29 @@
 package main
 
 import (
-	// Generated bindings
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/binary"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/collection_management"
 	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/document"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/fts"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/query_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/search_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/sqlpp"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_lookup"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_mutate"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/transactions"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/sqlpp_types"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
 	"github.com/couchbase/gocb/v2"
+	"github.com/couchbase/gocb/v2/search"
+	"github.com/couchbase/gocbcore/v10"
 )
 
 // This file contains the conversion functions for the options used in the document binding.
@@ -123,3 +141,985 @@ func UpsertOptions(o *document.DocumentUpsertOptions) *gocb.UpsertOptions {
 	}
 	return &gocb.UpsertOptions{}
 }
+
+// ExistsOptions
+func ExistsOptions(o *document.DocumentExistsOptions) *gocb.ExistsOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.ExistsOptions{
+		Timeout: timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// ScanType
+func ScanType(t *document.DocumentScanType) (gocb.ScanType, error) {
+	switch t.Discriminant() {
+	case document.DocumentScanTypeRange:
+		r, _ := t.GetRange()
+		return gocb.RangeScan{From: ScanTerm(r.Start), To: ScanTerm(r.End)}, nil
+	case document.DocumentScanTypePrefix:
+		prefix, _ := t.GetPrefix()
+		return gocb.NewRangeScanForPrefix(prefix), nil
+	case document.DocumentScanTypeSampling:
+		s, _ := t.GetSampling()
+		if s.Limit == 0 {
+			return nil, errors.New("sampling scan limit must be greater than 0")
+		}
+		scan := gocb.SamplingScan{Limit: s.Limit}
+		if s.Seed != nil {
+			scan.Seed = *s.Seed
+		}
+		return scan, nil
+	default:
+		return nil, fmt.Errorf("unknown scan type %v", t.Discriminant())
+	}
+}
+
+// ScanTerm
+func ScanTerm(t *document.DocumentScanTerm) *gocb.ScanTerm {
+	if t == nil {
+		return nil
+	}
+	return &gocb.ScanTerm{Term: t.Term, Exclusive: t.Exclusive}
+}
+
+// ScanOptions
+func ScanOptions(o *document.DocumentScanOptions) *gocb.ScanOptions {
+	if o == nil {
+		return nil
+	}
+	options := &gocb.ScanOptions{
+		Timeout:        timeoutFromNs(o.TimeoutNs),
+		IDsOnly:        o.IdsOnly,
+		BatchByteLimit: o.BatchByteLimit,
+		BatchItemLimit: o.BatchItemLimit,
+	}
+	if o.Concurrency != nil {
+		options.Concurrency = *o.Concurrency
+	}
+	return options
+}
+
+// Conversion functions for the options used in the binary binding.
+
+// IncrementOptions
+func IncrementOptions(delta uint64, o *binary.CounterOptions) (*gocb.IncrementOptions, error) {
+	initial, err := counterInitial(o)
+	if err != nil {
+		return nil, err
+	}
+	options := &gocb.IncrementOptions{Delta: delta, Initial: initial}
+	if o != nil {
+		options.Expiry = time.Duration(o.ExpiresInNs)
+		options.DurabilityLevel = DurabilityLevel(o.DurabilityLevel)
+		options.Timeout = timeoutFromNs(o.TimeoutNs)
+	}
+	return options, nil
+}
+
+// DecrementOptions
+func DecrementOptions(delta uint64, o *binary.CounterOptions) (*gocb.DecrementOptions, error) {
+	initial, err := counterInitial(o)
+	if err != nil {
+		return nil, err
+	}
+	options := &gocb.DecrementOptions{Delta: delta, Initial: initial}
+	if o != nil {
+		options.Expiry = time.Duration(o.ExpiresInNs)
+		options.DurabilityLevel = DurabilityLevel(o.DurabilityLevel)
+		options.Timeout = timeoutFromNs(o.TimeoutNs)
+	}
+	return options, nil
+}
+
+// counterInitial returns the initial value of a counter, negative values making the operation fail if the counter does not exist
+func counterInitial(o *binary.CounterOptions) (int64, error) {
+	if o == nil || o.Initial == nil {
+		return -1, nil
+	}
+	if *o.Initial > math.MaxInt64 {
+		return 0, fmt.Errorf("initial counter value %d is above %d", *o.Initial, int64(math.MaxInt64))
+	}
+	return int64(*o.Initial), nil
+}
+
+// AppendOptions
+func AppendOptions(o *binary.BinaryWriteOptions) *gocb.AppendOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.AppendOptions{
+		Cas:             gocb.Cas(o.Cas),
+		DurabilityLevel: DurabilityLevel(o.DurabilityLevel),
+		Timeout:         timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// PrependOptions
+func PrependOptions(o *binary.BinaryWriteOptions) *gocb.PrependOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.PrependOptions{
+		Cas:             gocb.Cas(o.Cas),
+		DurabilityLevel: DurabilityLevel(o.DurabilityLevel),
+		Timeout:         timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// Conversion functions for the options used in the subdocument-lookup binding.
+
+// LookupInSpecs
+func LookupInSpecs(operations []*subdocument_lookup.LookupOperation) ([]gocb.LookupInSpec, error) {
+	specs := make([]gocb.LookupInSpec, 0, len(operations))
+	for _, op := range operations {
+		spec, err := LookupInSpec(op)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// LookupInSpec
+func LookupInSpec(op *subdocument_lookup.LookupOperation) (gocb.LookupInSpec, error) {
+	if op == nil {
+		return gocb.LookupInSpec{}, errors.New("lookup operation must not be empty")
+	}
+	switch op.Discriminant() {
+	case subdocument_lookup.LookupOperationExists:
+		payload, _ := op.GetExists()
+		return gocb.ExistsSpec(payload.V0, &gocb.ExistsSpecOptions{IsXattr: lookupXattr(payload.V1)}), nil
+	case subdocument_lookup.LookupOperationGet:
+		payload, _ := op.GetGet()
+		return gocb.GetSpec(payload.V0, &gocb.GetSpecOptions{IsXattr: lookupXattr(payload.V1)}), nil
+	case subdocument_lookup.LookupOperationCount:
+		payload, _ := op.GetCount()
+		return gocb.CountSpec(payload.V0, &gocb.CountSpecOptions{IsXattr: lookupXattr(payload.V1)}), nil
+	default:
+		return gocb.LookupInSpec{}, fmt.Errorf("unsupported lookup operation %s", op)
+	}
+}
+
+func lookupXattr(o *subdocument_lookup.LookupGetOptions) bool {
+	return o != nil && isSet(o.Xattr)
+}
+
+// LookupInOptions
+func LookupInOptions(o *subdocument_lookup.LookupOptions) *gocb.LookupInOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.LookupInOptions{
+		Timeout: timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// LookupInAnyReplicaOptions
+func LookupInAnyReplicaOptions(o *subdocument_lookup.LookupOptions) *gocb.LookupInAnyReplicaOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.LookupInAnyReplicaOptions{
+		Timeout: timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// LookupInAllReplicaOptions
+func LookupInAllReplicaOptions(o *subdocument_lookup.LookupOptions) *gocb.LookupInAllReplicaOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.LookupInAllReplicaOptions{
+		Timeout: timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// timeoutFromNs converts an optional timeout in nanoseconds, leaving the default timeout in place when unset
+func timeoutFromNs(timeoutNs *uint64) time.Duration {
+	if timeoutNs == nil {
+		return 0
+	}
+	return time.Duration(*timeoutNs)
+}
+
+// Conversion functions for the options used in the subdocument-mutate binding.
+
+// MutateInSpecs
+func MutateInSpecs(operations []*subdocument_mutate.MutateOperation) ([]gocb.MutateInSpec, error) {
+	specs := make([]gocb.MutateInSpec, 0, len(operations))
+	for _, op := range operations {
+		spec, err := MutateInSpec(op)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// MutateInSpec
+func MutateInSpec(op *subdocument_mutate.MutateOperation) (gocb.MutateInSpec, error) {
+	if op == nil {
+		return gocb.MutateInSpec{}, errors.New("mutate operation must not be empty")
+	}
+	switch op.Discriminant() {
+	case subdocument_mutate.MutateOperationInsert:
+		payload, _ := op.GetInsert()
+		value, err := DocumentJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.InsertSpec(payload.V0, value, &gocb.InsertSpecOptions{
+			CreatePath: payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:    payload.V2 != nil && isSet(payload.V2.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationUpsert:
+		payload, _ := op.GetUpsert()
+		value, err := DocumentJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.UpsertSpec(payload.V0, value, &gocb.UpsertSpecOptions{
+			CreatePath: payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:    payload.V2 != nil && isSet(payload.V2.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationReplace:
+		payload, _ := op.GetReplace()
+		value, err := DocumentJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ReplaceSpec(payload.V0, value, &gocb.ReplaceSpecOptions{
+			IsXattr: payload.V2 != nil && isSet(payload.V2.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationRemove:
+		payload, _ := op.GetRemove()
+		return gocb.RemoveSpec(payload.V0, &gocb.RemoveSpecOptions{
+			IsXattr: payload.V1 != nil && isSet(payload.V1.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationIncrement:
+		payload, _ := op.GetIncrement()
+		return gocb.IncrementSpec(payload.V0, payload.V1, CounterSpecOptions(payload.V2)), nil
+	case subdocument_mutate.MutateOperationDecrement:
+		payload, _ := op.GetDecrement()
+		return gocb.DecrementSpec(payload.V0, payload.V1, CounterSpecOptions(payload.V2)), nil
+	case subdocument_mutate.MutateOperationArrayAddUnique:
+		payload, _ := op.GetArrayAddUnique()
+		value, err := DocumentJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayAddUniqueSpec(payload.V0, value, &gocb.ArrayAddUniqueSpecOptions{
+			CreatePath: payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:    payload.V2 != nil && payload.V2.Xattr,
+		}), nil
+	case subdocument_mutate.MutateOperationArrayAppend:
+		payload, _ := op.GetArrayAppend()
+		value, err := DocumentJSON(payload.V0.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayAppendSpec(payload.V0.V0, value, &gocb.ArrayAppendSpecOptions{
+			CreatePath: payload.V1 != nil && payload.V1.CreatePath,
+			IsXattr:    payload.V1 != nil && isSet(payload.V1.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationArrayAppendMulti:
+		payload, _ := op.GetArrayAppendMulti()
+		values, err := DocumentsJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayAppendSpec(payload.V0, values, &gocb.ArrayAppendSpecOptions{
+			CreatePath:  payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:     payload.V2 != nil && isSet(payload.V2.Xattr),
+			HasMultiple: true,
+		}), nil
+	case subdocument_mutate.MutateOperationArrayInsert:
+		payload, _ := op.GetArrayInsert()
+		value, err := DocumentJSON(payload.V0.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayInsertSpec(payload.V0.V0, value, &gocb.ArrayInsertSpecOptions{
+			CreatePath: payload.V1 != nil && payload.V1.CreatePath,
+			IsXattr:    payload.V1 != nil && isSet(payload.V1.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationArrayInsertMulti:
+		payload, _ := op.GetArrayInsertMulti()
+		values, err := DocumentsJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayInsertSpec(payload.V0, values, &gocb.ArrayInsertSpecOptions{
+			CreatePath:  payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:     payload.V2 != nil && isSet(payload.V2.Xattr),
+			HasMultiple: true,
+		}), nil
+	case subdocument_mutate.MutateOperationArrayPrepend:
+		payload, _ := op.GetArrayPrepend()
+		value, err := DocumentJSON(payload.V0.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayPrependSpec(payload.V0.V0, value, &gocb.ArrayPrependSpecOptions{
+			CreatePath: payload.V1 != nil && payload.V1.CreatePath,
+			IsXattr:    payload.V1 != nil && isSet(payload.V1.Xattr),
+		}), nil
+	case subdocument_mutate.MutateOperationArrayPrependMulti:
+		payload, _ := op.GetArrayPrependMulti()
+		values, err := DocumentsJSON(payload.V1)
+		if err != nil {
+			return gocb.MutateInSpec{}, err
+		}
+		return gocb.ArrayPrependSpec(payload.V0, values, &gocb.ArrayPrependSpecOptions{
+			CreatePath:  payload.V2 != nil && payload.V2.CreatePath,
+			IsXattr:     payload.V2 != nil && isSet(payload.V2.Xattr),
+			HasMultiple: true,
+		}), nil
+	default:
+		return gocb.MutateInSpec{}, fmt.Errorf("unsupported mutate operation %s", op)
+	}
+}
+
+// CounterSpecOptions
+func CounterSpecOptions(o *subdocument_mutate.CounterOperationOptions) *gocb.CounterSpecOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.CounterSpecOptions{
+		CreatePath: o.CreatePath,
+		IsXattr:    isSet(o.Xattr),
+	}
+}
+
+// MutateInOptions
+func MutateInOptions(o *subdocument_mutate.MutateOptions) *gocb.MutateInOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.MutateInOptions{
+		Expiry:          time.Duration(o.ExpiresInNs),
+		Cas:             gocb.Cas(o.Cas),
+		PersistTo:       uint(o.PersistTo),
+		ReplicateTo:     uint(o.ReplicateTo),
+		DurabilityLevel: DurabilityLevel(o.DurabilityLevel),
+		StoreSemantic:   StoreSemantics(o.StoreSemantics),
+		Timeout:         time.Duration(o.TimeoutNs),
+		PreserveExpiry:  o.PreserveExpiry,
+	}
+}
+
+// StoreSemantics
+func StoreSemantics(s subdocument_mutate.StoreSemantics) gocb.StoreSemantics {
+	switch s {
+	case subdocument_mutate.StoreSemantics_Upsert:
+		return gocb.StoreSemanticsUpsert
+	case subdocument_mutate.StoreSemantics_Insert:
+		return gocb.StoreSemanticsInsert
+	default:
+		return gocb.StoreSemanticsReplace
+	}
+}
+
+// DurabilityLevel
+func DurabilityLevel(l types.DurabilityLevel) gocb.DurabilityLevel {
+	switch l {
+	case types.DurabilityLevel_None:
+		return gocb.DurabilityLevelNone
+	case types.DurabilityLevel_ReplicateMajority:
+		return gocb.DurabilityLevelMajority
+	case types.DurabilityLevel_ReplicateMajorityPersistMaster:
+		return gocb.DurabilityLevelMajorityAndPersistOnMaster
+	case types.DurabilityLevel_PersistMajority:
+		return gocb.DurabilityLevelPersistToMajority
+	default:
+		return gocb.DurabilityLevelUnknown
+	}
+}
+
+// DocumentJSON extracts the raw JSON of a document so it can be embedded into a subdocument operation
+func DocumentJSON(doc *types.Document) (json.RawMessage, error) {
+	if doc == nil {
+		return nil, errors.New("document must not be empty")
+	}
+	raw, ok := doc.GetRaw()
+	if !ok {
+		return nil, errors.New("only raw JSON documents are supported")
+	}
+	if !json.Valid([]byte(raw)) {
+		return nil, errors.New("document is not valid JSON")
+	}
+	return json.RawMessage(raw), nil
+}
+
+// DocumentsJSON
+func DocumentsJSON(docs []*types.Document) ([]json.RawMessage, error) {
+	values := make([]json.RawMessage, 0, len(docs))
+	for _, doc := range docs {
+		value, err := DocumentJSON(doc)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func isSet(b *bool) bool {
+	return b != nil && *b
+}
+
+// Conversion functions for the options used in the sqlpp binding.
+
+// QueryOptions
+func QueryOptions(o *sqlpp.SqlppQueryOptions, params []*sqlpp.SqlppValue) (*gocb.QueryOptions, error) {
+	positionalParameters, err := SqlppParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return &gocb.QueryOptions{PositionalParameters: positionalParameters}, nil
+	}
+	options := &gocb.QueryOptions{
+		PositionalParameters: positionalParameters,
+		ScanCap:              o.ScanCap,
+		PipelineBatch:        o.PipelineBatch,
+		PipelineCap:          o.PipelineCap,
+		ScanWait:             time.Duration(o.ScanWaitNs),
+		Readonly:             o.Readonly,
+		MaxParallelism:       o.MaxParallelism,
+		Metrics:              o.Metrics,
+		Adhoc:                o.AdHoc,
+		Timeout:              time.Duration(o.TimeoutNs),
+		PreserveExpiry:       o.PreserveExpiry,
+		FlexIndex:            o.UseFlexIndex,
+	}
+	if o.ClientContextId != nil {
+		options.ClientContextID = *o.ClientContextId
+	}
+	if o.Profile != nil {
+		options.Profile = QueryProfileMode(*o.Profile)
+	}
+	// Scan consistency and consistent-with are mutually exclusive, the latter taking precedence
+	if o.ConsistentWith != nil {
+		options.ConsistentWith = MutationState(o.ConsistentWith)
+	} else {
+		options.ScanConsistency = QueryScanConsistency(o.ScanConsistency)
+	}
+	return options, nil
+}
+
+// QueryScanConsistency
+func QueryScanConsistency(c types.QueryScanConsistency) gocb.QueryScanConsistency {
+	switch c {
+	case types.QueryScanConsistency_RequestPlus:
+		return gocb.QueryScanConsistencyRequestPlus
+	default:
+		return gocb.QueryScanConsistencyNotBounded
+	}
+}
+
+// QueryProfileMode
+func QueryProfileMode(m types.QueryProfileMode) gocb.QueryProfileMode {
+	switch m {
+	case types.QueryProfileMode_Phases:
+		return gocb.QueryProfileModePhases
+	case types.QueryProfileMode_Timings:
+		return gocb.QueryProfileModeTimings
+	default:
+		return gocb.QueryProfileModeNone
+	}
+}
+
+// MutationState
+func MutationState(s *types.MutationState) *gocb.MutationState {
+	state := gocb.NewMutationState()
+	for _, token := range s.Tokens {
+		if token == nil {
+			continue
+		}
+		state.Internal().Add(token.BucketName, gocbcore.MutationToken{
+			VbID:   uint16(token.PartitionId),
+			VbUUID: gocbcore.VbUUID(token.PartitionUuid),
+			SeqNo:  gocbcore.SeqNo(token.SequenceNumber),
+		})
+	}
+	return state
+}
+
+// SqlppParams converts positional query parameters to values the query service can encode
+func SqlppParams(params []*sqlpp.SqlppValue) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(params))
+	for idx, param := range params {
+		value, err := SqlppParam(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query parameter $%d: %w", idx+1, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// SqlppParam
+func SqlppParam(v *sqlpp.SqlppValue) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	switch v.Discriminant() {
+	case sqlpp_types.SqlppValueNull:
+		return nil, nil
+	case sqlpp_types.SqlppValueBoolean:
+		value, _ := v.GetBoolean()
+		return value, nil
+	case sqlpp_types.SqlppValueInteger:
+		value, _ := v.GetInteger()
+		return value, nil
+	case sqlpp_types.SqlppValueFloat:
+		value, _ := v.GetFloat()
+		return value, nil
+	case sqlpp_types.SqlppValueString:
+		value, _ := v.GetString()
+		return value, nil
+	case sqlpp_types.SqlppValueArray:
+		value, _ := v.GetArray()
+		return sqlppJSON(value, '[', "array")
+	case sqlpp_types.SqlppValueObject:
+		value, _ := v.GetObject()
+		return sqlppJSON(value, '{', "object")
+	default:
+		return nil, fmt.Errorf("unsupported sqlpp value %s", v)
+	}
+}
+
+// sqlppJSON validates that a JSON string holds the expected kind of value (array or object)
+func sqlppJSON(value string, open byte, kind string) (json.RawMessage, error) {
+	trimmed := strings.TrimSpace(value)
+	if len(trimmed) == 0 || trimmed[0] != open || !json.Valid([]byte(trimmed)) {
+		return nil, fmt.Errorf("expected a JSON %s", kind)
+	}
+	return json.RawMessage(trimmed), nil
+}
+
+// Conversion functions for the options used in the fts binding.
+
+// SearchOptions
+func SearchOptions(o *fts.SearchOptions) (*gocb.SearchOptions, error) {
+	if o == nil {
+		return nil, nil
+	}
+	options := &gocb.SearchOptions{
+		ScanConsistency:  gocb.SearchScanConsistencyNotBounded,
+		Limit:            o.Limit,
+		Skip:             o.Skip,
+		Explain:          o.Explain,
+		Fields:           o.Fields,
+		DisableScoring:   o.DisableScoring,
+		Timeout:          time.Duration(o.TimeoutNs),
+		IncludeLocations: o.IncludeLocations,
+	}
+	if o.Highlight != nil {
+		options.Highlight = SearchHighlightOptions(o.Highlight)
+	}
+	for _, sort := range o.Sort {
+		if sort == nil {
+			continue
+		}
+		options.Sort = append(options.Sort, search.NewSearchSortField(sort.V0).Descending(sort.V1 == types.SortDirection_Desc))
+	}
+	if o.ConsistentWith != nil && len(o.ConsistentWith.Tokens) > 0 {
+		options.ConsistentWith = MutationState(o.ConsistentWith)
+	}
+	for _, collection := range o.Collections {
+		if collection == nil {
+			continue
+		}
+		options.Collections = append(options.Collections, collection.Name)
+	}
+	if len(o.Facets) > 0 {
+		options.Facets = make(map[string]search.Facet, len(o.Facets))
+		for _, facet := range o.Facets {
+			if facet == nil {
+				continue
+			}
+			searchFacet, err := SearchFacet(facet.V1)
+			if err != nil {
+				return nil, fmt.Errorf("invalid facet %s: %w", facet.V0, err)
+			}
+			options.Facets[facet.V0] = searchFacet
+		}
+	}
+	return options, nil
+}
+
+// SearchFacet
+func SearchFacet(f *fts.Facet) (search.Facet, error) {
+	if f == nil {
+		return nil, errors.New("facet must not be empty")
+	}
+	switch f.Discriminant() {
+	case fts.FacetTerm:
+		payload, _ := f.GetTerm()
+		return search.NewTermFacet(payload.Field, payload.Size), nil
+	case fts.FacetNumeric:
+		payload, _ := f.GetNumeric()
+		facet := search.NewNumericFacet(payload.Field, payload.Size)
+		for _, r := range payload.Ranges {
+			facet.AddRange(r.Name, r.Min, r.Max)
+		}
+		return facet, nil
+	case fts.FacetDateRange:
+		payload, _ := f.GetDateRange()
+		facet := search.NewDateFacet(payload.Field, payload.Size)
+		for _, r := range payload.Ranges {
+			facet.AddRange(r.Name, r.Start, r.End)
+		}
+		return facet, nil
+	default:
+		return nil, fmt.Errorf("unsupported facet %s", f)
+	}
+}
+
+// SearchHighlightOptions
+func SearchHighlightOptions(o *fts.SearchHighlightOptions) *gocb.SearchHighlightOptions {
+	options := &gocb.SearchHighlightOptions{Fields: o.Fields}
+	switch o.Style {
+	case fts.SearchHighlightStyle_Html:
+		options.Style = gocb.HTMLHighlightStyle
+	case fts.SearchHighlightStyle_Ansi:
+		options.Style = gocb.AnsiHightlightStyle
+	default:
+		options.Style = gocb.DefaultHighlightStyle
+	}
+	return options
+}
+
+// Conversion functions for the options used in the transactions binding.
+
+// TransactionOptions
+func TransactionOptions(o *transactions.CreateTxOptions) *gocb.TransactionOptions {
+	if o == nil {
+		return nil
+	}
+	options := &gocb.TransactionOptions{
+		Timeout: time.Duration(o.TimeoutNs),
+	}
+	if o.DurabilityLevel != nil {
+		options.DurabilityLevel = DurabilityLevel(*o.DurabilityLevel)
+	}
+	return options
+}
+
+// TransactionQueryOptions
+func TransactionQueryOptions(o *transactions.TransactionQueryOptions, params []*transactions.SqlppValue) (*gocb.TransactionQueryOptions, error) {
+	positionalParameters, err := SqlppParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if o == nil {
+		return &gocb.TransactionQueryOptions{PositionalParameters: positionalParameters}, nil
+	}
+	options := &gocb.TransactionQueryOptions{
+		PositionalParameters: positionalParameters,
+		ScanConsistency:      QueryScanConsistency(o.ScanConsistency),
+		ScanCap:              o.ScanCap,
+		PipelineBatch:        o.PipelineBatch,
+		PipelineCap:          o.PipelineCap,
+		ScanWait:             time.Duration(o.ScanWaitNs),
+		Readonly:             o.Readonly,
+	}
+	if o.ClientContextId != nil {
+		options.ClientContextID = *o.ClientContextId
+	}
+	if o.Profile != nil {
+		options.Profile = QueryProfileMode(*o.Profile)
+	}
+	return options, nil
+}
+
+// Conversion functions for the options used in the collection-management binding.
+
+// GetAllScopesOptions
+func GetAllScopesOptions(o *collection_management.CollectionManagementOptions) *gocb.GetAllScopesOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.GetAllScopesOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// CreateScopeOptions
+func CreateScopeOptions(o *collection_management.CollectionManagementOptions) *gocb.CreateScopeOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.CreateScopeOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// DropScopeOptions
+func DropScopeOptions(o *collection_management.CollectionManagementOptions) *gocb.DropScopeOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.DropScopeOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// CreateCollectionOptions
+func CreateCollectionOptions(o *collection_management.CollectionManagementOptions) *gocb.CreateCollectionOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.CreateCollectionOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// UpdateCollectionOptions
+func UpdateCollectionOptions(o *collection_management.CollectionManagementOptions) *gocb.UpdateCollectionOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.UpdateCollectionOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// DropCollectionOptions
+func DropCollectionOptions(o *collection_management.CollectionManagementOptions) *gocb.DropCollectionOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.DropCollectionOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// CreateCollectionSettings
+func CreateCollectionSettings(s *collection_management.CollectionSettings) *gocb.CreateCollectionSettings {
+	if s == nil {
+		return nil
+	}
+	return &gocb.CreateCollectionSettings{
+		MaxExpiry: CollectionMaxExpiry(s.MaxExpiry),
+		History:   CollectionHistorySettings(s.History),
+	}
+}
+
+// UpdateCollectionSettings
+func UpdateCollectionSettings(s *collection_management.CollectionSettings) gocb.UpdateCollectionSettings {
+	if s == nil {
+		return gocb.UpdateCollectionSettings{}
+	}
+	return gocb.UpdateCollectionSettings{
+		MaxExpiry: CollectionMaxExpiry(s.MaxExpiry),
+		History:   CollectionHistorySettings(s.History),
+	}
+}
+
+// CollectionMaxExpiry converts a maximum expiry, 0 standing for the bucket default and -1 second for no expiry
+func CollectionMaxExpiry(e *collection_management.CollectionMaxExpiry) time.Duration {
+	if e == nil {
+		return 0
+	}
+	switch e.Discriminant() {
+	case collection_management.CollectionMaxExpiryNoExpiry:
+		return -time.Second
+	case collection_management.CollectionMaxExpiryExpiresInNs:
+		expiresInNs, _ := e.GetExpiresInNs()
+		return time.Duration(expiresInNs)
+	default:
+		return 0
+	}
+}
+
+// CollectionHistorySettings
+func CollectionHistorySettings(history *bool) *gocb.CollectionHistorySettings {
+	if history == nil {
+		return nil
+	}
+	return &gocb.CollectionHistorySettings{Enabled: *history}
+}
+
+// Conversion functions for the options used in the query-index-management binding.
+
+// GetAllQueryIndexesOptions
+func GetAllQueryIndexesOptions(o *query_index_management.QueryIndexManagementOptions) *gocb.GetAllQueryIndexesOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.GetAllQueryIndexesOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// CreateQueryIndexOptions
+func CreateQueryIndexOptions(o *query_index_management.CreateQueryIndexOptions) *gocb.CreateQueryIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.CreateQueryIndexOptions{
+		IgnoreIfExists: o.IgnoreIfExists,
+		Deferred:       o.Deferred,
+		NumReplicas:    numReplicas(o.NumReplicas),
+		Timeout:        timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// CreatePrimaryQueryIndexOptions, which are never nil so that the name of the index is known
+func CreatePrimaryQueryIndexOptions(o *query_index_management.CreatePrimaryQueryIndexOptions) *gocb.CreatePrimaryQueryIndexOptions {
+	if o == nil {
+		return &gocb.CreatePrimaryQueryIndexOptions{}
+	}
+	options := &gocb.CreatePrimaryQueryIndexOptions{
+		IgnoreIfExists: o.IgnoreIfExists,
+		Deferred:       o.Deferred,
+		NumReplicas:    numReplicas(o.NumReplicas),
+		Timeout:        timeoutFromNs(o.TimeoutNs),
+	}
+	if o.CustomName != nil {
+		options.CustomName = *o.CustomName
+	}
+	return options
+}
+
+// BuildDeferredQueryIndexOptions
+func BuildDeferredQueryIndexOptions(o *query_index_management.QueryIndexManagementOptions) *gocb.BuildDeferredQueryIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.BuildDeferredQueryIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// DropQueryIndexOptions
+func DropQueryIndexOptions(o *query_index_management.DropQueryIndexOptions) *gocb.DropQueryIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.DropQueryIndexOptions{
+		IgnoreIfNotExists: o.IgnoreIfNotExists,
+		Timeout:           timeoutFromNs(o.TimeoutNs),
+	}
+}
+
+// DropPrimaryQueryIndexOptions, which are never nil so that the name of the index is known
+func DropPrimaryQueryIndexOptions(o *query_index_management.DropPrimaryQueryIndexOptions) *gocb.DropPrimaryQueryIndexOptions {
+	if o == nil {
+		return &gocb.DropPrimaryQueryIndexOptions{}
+	}
+	options := &gocb.DropPrimaryQueryIndexOptions{
+		IgnoreIfNotExists: o.IgnoreIfNotExists,
+		Timeout:           timeoutFromNs(o.TimeoutNs),
+	}
+	if o.CustomName != nil {
+		options.CustomName = *o.CustomName
+	}
+	return options
+}
+
+// WatchQueryIndexOptions
+func WatchQueryIndexOptions(o *query_index_management.WatchQueryIndexesOptions) *gocb.WatchQueryIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.WatchQueryIndexOptions{WatchPrimary: o.WatchPrimary}
+}
+
+func numReplicas(n *uint32) int {
+	if n == nil {
+		return 0
+	}
+	return int(*n)
+}
+
+// Conversion functions for the options used in the search-index-management binding.
+
+// SearchIndexDefinition converts a search index, whose parameters must be JSON objects
+func SearchIndexDefinition(index *search_index_management.SearchIndex) (gocb.SearchIndex, error) {
+	definition := gocb.SearchIndex{
+		UUID:       index.Uuid,
+		Name:       index.Name,
+		SourceName: index.SourceName,
+		Type:       index.IndexType,
+		SourceUUID: index.SourceUuid,
+		SourceType: index.SourceType,
+	}
+	var err error
+	if definition.Params, err = searchIndexParams("params", index.Params); err != nil {
+		return definition, err
+	}
+	if definition.SourceParams, err = searchIndexParams("source-params", index.SourceParams); err != nil {
+		return definition, err
+	}
+	if definition.PlanParams, err = searchIndexParams("plan-params", index.PlanParams); err != nil {
+		return definition, err
+	}
+	return definition, nil
+}
+
+func searchIndexParams(name string, params *string) (map[string]interface{}, error) {
+	if params == nil {
+		return nil, nil
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(*params), &decoded); err != nil {
+		return nil, fmt.Errorf("%s must be a JSON object: %w", name, err)
+	}
+	return decoded, nil
+}
+
+// GetAllSearchIndexOptions
+func GetAllSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.GetAllSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.GetAllSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// GetSearchIndexOptions
+func GetSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.GetSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.GetSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// UpsertSearchIndexOptions
+func UpsertSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.UpsertSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.UpsertSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// DropSearchIndexOptions
+func DropSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.DropSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.DropSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// PauseIngestSearchIndexOptions
+func PauseIngestSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.PauseIngestSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.PauseIngestSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// ResumeIngestSearchIndexOptions
+func ResumeIngestSearchIndexOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.ResumeIngestSearchIndexOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.ResumeIngestSearchIndexOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// AnalyzeDocumentOptions
+func AnalyzeDocumentOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.AnalyzeDocumentOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.AnalyzeDocumentOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
+
+// GetIndexedDocumentsCountOptions
+func GetIndexedDocumentsCountOptions(o *search_index_management.SearchIndexManagementOptions) *gocb.GetIndexedDocumentsCountOptions {
+	if o == nil {
+		return nil
+	}
+	return &gocb.GetIndexedDocumentsCountOptions{Timeout: timeoutFromNs(o.TimeoutNs)}
+}
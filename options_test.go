@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/couchbase/gocb/v2"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/binary"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/document"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/sqlpp"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/sqlpp_types"
+)
+
+func TestSqlppParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       *sqlpp.SqlppValue
+		expected    interface{}
+		expectError bool
+	}{
+		{"null", sqlpp_types.NewSqlppValueNull(), nil, false},
+		{"boolean", sqlpp_types.NewSqlppValueBoolean(true), true, false},
+		{"integer", sqlpp_types.NewSqlppValueInteger(42), int64(42), false},
+		{"float", sqlpp_types.NewSqlppValueFloat(1.5), 1.5, false},
+		{"string", sqlpp_types.NewSqlppValueString("airline"), "airline", false},
+		{"array", sqlpp_types.NewSqlppValueArray(` [1, "two"] `), json.RawMessage(`[1, "two"]`), false},
+		{"object", sqlpp_types.NewSqlppValueObject(`{"name": "test"}`), json.RawMessage(`{"name": "test"}`), false},
+		{"invalid array", sqlpp_types.NewSqlppValueArray(`[1,`), nil, true},
+		{"object as array", sqlpp_types.NewSqlppValueArray(`{"name": "test"}`), nil, true},
+		{"array as object", sqlpp_types.NewSqlppValueObject(`[]`), nil, true},
+	}
+
+	for _, test := range tests {
+		values, err := SqlppParams([]*sqlpp.SqlppValue{test.value})
+		if test.expectError {
+			if err == nil {
+				t.Errorf("expected error for %s param, got none", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("did not expect error for %s param, got %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(values, []interface{}{test.expected}) {
+			t.Errorf("expected %#v for %s param, got %#v", test.expected, test.name, values[0])
+		}
+	}
+}
+
+func TestIncrementOptions(t *testing.T) {
+	initial := uint64(10)
+	tests := []struct {
+		name     string
+		options  *binary.CounterOptions
+		expected int64
+	}{
+		{"no options", nil, -1},
+		{"no initial value", &binary.CounterOptions{}, -1},
+		{"initial value", &binary.CounterOptions{Initial: &initial}, 10},
+	}
+
+	for _, test := range tests {
+		options, err := IncrementOptions(5, test.options)
+		if err != nil {
+			t.Fatalf("unexpected error for %s: %v", test.name, err)
+		}
+		if options.Delta != 5 {
+			t.Errorf("expected delta 5 for %s, got %d", test.name, options.Delta)
+		}
+		if options.Initial != test.expected {
+			t.Errorf("expected initial %d for %s, got %d", test.expected, test.name, options.Initial)
+		}
+	}
+}
+
+func TestIncrementOptionsInitialOverflow(t *testing.T) {
+	initial := uint64(math.MaxInt64) + 1
+	if _, err := IncrementOptions(5, &binary.CounterOptions{Initial: &initial}); err == nil {
+		t.Errorf("expected an error for initial value %d", initial)
+	}
+}
+
+func TestScanType(t *testing.T) {
+	seed := uint64(42)
+	tests := []struct {
+		name     string
+		scanType *document.DocumentScanType
+		expected gocb.ScanType
+	}{
+		{
+			"range",
+			document.NewDocumentScanTypeRange(&document.DocumentRangeScan{
+				Start: &document.DocumentScanTerm{Term: "a"},
+				End:   &document.DocumentScanTerm{Term: "b", Exclusive: true},
+			}),
+			gocb.RangeScan{From: &gocb.ScanTerm{Term: "a"}, To: &gocb.ScanTerm{Term: "b", Exclusive: true}},
+		},
+		{"unbounded range", document.NewDocumentScanTypeRange(&document.DocumentRangeScan{}), gocb.RangeScan{}},
+		{"prefix", document.NewDocumentScanTypePrefix("user::"), gocb.NewRangeScanForPrefix("user::")},
+		{
+			"sampling",
+			document.NewDocumentScanTypeSampling(&document.DocumentSamplingScan{Limit: 10, Seed: &seed}),
+			gocb.SamplingScan{Limit: 10, Seed: 42},
+		},
+	}
+
+	for _, test := range tests {
+		actual, err := ScanType(test.scanType)
+		if err != nil {
+			t.Errorf("unexpected error for %s scan: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(actual, test.expected) {
+			t.Errorf("expected %#v for %s scan, got %#v", test.expected, test.name, actual)
+		}
+	}
+
+	if _, err := ScanType(document.NewDocumentScanTypeSampling(&document.DocumentSamplingScan{})); err == nil {
+		t.Error("expected an error for a sampling scan without a limit")
+	}
+}
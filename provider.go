@@ -1,33 +1,83 @@
-//go:generate wit-bindgen-wrpc go --out-dir bindings --package github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings wit
-
 package main
 
 import (
+	"context"
+	"errors"
 	"time"
 
-	gocbt "github.com/couchbase/gocb-opentelemetry"
 	"github.com/couchbase/gocb/v2"
-	"go.opentelemetry.io/otel"
 	"go.wasmcloud.dev/provider"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/change_handler"
 )
 
-// The primary function for connecting a sourceId component to a Couchbase cluster
+// A connection to a Couchbase cluster, along with the collection configured on the link
+type CouchbaseConnection struct {
+	Cluster    *gocb.Cluster
+	Collection *gocb.Collection
+	// How long a SQL++ query cursor, KV scan cursor or key listing opened on this link may remain unused
+	CursorIdleTimeout time.Duration
+	// How long a transaction started on this link may remain unused
+	TransactionIdleTimeout time.Duration
+	// Whether management operations may be performed on this link
+	AllowManagement bool
+	// Keyspaces that keyvalue stores are opened on, keyed by alias
+	KeyvalueStores map[string]KeyvalueKeyspace
+	// Collection that blobstore containers and objects are stored in
+	BlobstoreCollection *gocb.Collection
+
+	// Slots of the documents being processed by bulk operations, shared by all the bulk operations
+	// on this link so that at most `bulkConcurrency` documents are processed concurrently
+	bulkSlots chan struct{}
+	// The lease on the cluster, which may be shared with other links
+	lease *clusterLease
+}
+
+// close releases the cluster of the connection, which is closed once no other link uses it
+func (c *CouchbaseConnection) close() error {
+	if c.lease == nil {
+		return nil
+	}
+	return c.lease.release()
+}
+
+// The primary function for connecting a sourceId component to a Couchbase cluster, in the background
 func (h *Handler) updateCouchbaseCluster(sourceId string, linkName string, connectionArgs CouchbaseConnectionArgs) {
-	// Connect to the cluster
-	cluster, err := gocb.Connect(connectionArgs.ConnectionString, gocb.ClusterOptions{
-		Username: connectionArgs.Username,
-		Password: connectionArgs.Password,
-		Tracer:   gocbt.NewOpenTelemetryRequestTracer(otel.GetTracerProvider()),
-	})
+	link := newLinkConnection(sourceId, linkName, connectionArgs, h.connectCouchbase, pingCouchbase, h.Logger)
+
+	// A link that is put again with a different config replaces the connection it previously used,
+	// which keeps serving requests until the new connection is ready
+	if !h.links.put(link) {
+		h.Logger.Info("Link config did not change, keeping its connection", "sourceId", sourceId, "link", linkName)
+		return
+	}
+	link.start()
+}
+
+// Stop connecting a link, and release the cluster of its connection
+func (h *Handler) closeConnection(link *linkConnection) {
+	if err := link.stop(); err != nil {
+		h.Logger.Warn("Error closing cluster connection", "sourceId", link.sourceId, "link", link.linkName, "error", err)
+	}
+}
+
+// Connect to the cluster and collection described by the config of a link
+func (h *Handler) connectCouchbase(connectionArgs CouchbaseConnectionArgs) (*CouchbaseConnection, error) {
+	lease, err := h.clusters.acquire(newClusterIdentity(connectionArgs), connectionArgs.Password)
 	if err != nil {
 		h.Logger.Error("unable to connect to couchbase cluster", "error", err)
-		return
+		return nil, err
+	}
+	if lease.rotated {
+		h.Logger.Info("Rotated cluster credentials", "connectionString", connectionArgs.ConnectionString, "username", connectionArgs.Username)
 	}
 
-	bucket := cluster.Bucket(connectionArgs.BucketName)
+	bucket := lease.bucket(connectionArgs.BucketName)
 	if err = bucket.WaitUntilReady(5*time.Second, nil); err != nil {
 		h.Logger.Error("unable to connect to couchbase bucket", "error", err)
-		return
+		_ = lease.release()
+		return nil, err
 	}
 
 	var collection *gocb.Collection
@@ -39,16 +89,61 @@ func (h *Handler) updateCouchbaseCluster(sourceId string, linkName string, conne
 	} else {
 		collection = bucket.DefaultCollection()
 	}
+	blobstoreCollection := collection
+	if keyspace := connectionArgs.BlobstoreCollection; keyspace != nil {
+		blobstoreCollection = bucket.Scope(keyspace.ScopeName).Collection(keyspace.CollectionName)
+	}
 
-	// Store the connection
-	if h.clusterConnections == nil {
-		h.clusterConnections = make(map[string]map[string]*gocb.Collection)
+	return &CouchbaseConnection{
+		Cluster:                lease.cluster,
+		Collection:             collection,
+		CursorIdleTimeout:      connectionArgs.CursorIdleTimeout,
+		TransactionIdleTimeout: connectionArgs.TransactionIdleTimeout,
+		AllowManagement:        connectionArgs.AllowManagement,
+		KeyvalueStores:         connectionArgs.KeyvalueStores,
+		BlobstoreCollection:    blobstoreCollection,
+		bulkSlots:              make(chan struct{}, connectionArgs.BulkConcurrency),
+		lease:                  lease,
+	}, nil
+}
+
+// Deliver the changes made to the collection of a link to its target component. The link connects in the
+// background like target links do, and changes are delivered once it is connected
+func (h *Handler) subscribeToChanges(target string, linkName string, connectionArgs CouchbaseConnectionArgs) {
+	client := h.OutgoingRpcClient(target)
+	deliver := func(ctx context.Context, change documentChange) error {
+		result, err := change_handler.OnChange(ctx, client, DocumentChange(change))
+		if err != nil {
+			return err
+		}
+		if result.Err != nil {
+			return errors.New(*result.Err)
+		}
+		return nil
 	}
-	if h.clusterConnections[sourceId] == nil {
-		h.clusterConnections[sourceId] = make(map[string]*gocb.Collection)
+	link := newLinkConnection(target, linkName, connectionArgs, h.connectCouchbase, pingCouchbase, h.Logger)
+	subscription := newChangeSubscription(
+		target,
+		linkName,
+		newDcpFeed(link),
+		newCollectionCheckpoints(link, target),
+		deliver,
+		h.Logger,
+	)
+
+	// A link that is put again replaces the previous subscription, which resumes from its checkpoint
+	if previous, exists := h.changeSubscriptions.put(target, linkName, &linkedChangeSubscription{subscription, link}); exists {
+		h.stopSubscription(target, linkName, previous)
 	}
+	link.start()
+	subscription.start()
+}
 
-	h.clusterConnections[sourceId][linkName] = collection
+// Stop delivering changes to the target component of a link, and release the cluster of its link
+func (h *Handler) stopSubscription(target string, linkName string, subscription *linkedChangeSubscription) {
+	if err := subscription.stop(); err != nil {
+		h.Logger.Warn("Error closing change feed connection", "target", target, "link", linkName, "error", err)
+	}
 }
 
 // Provider handler functions
@@ -65,11 +160,31 @@ func (h *Handler) handleNewTargetLink(link provider.InterfaceLinkDefinition) err
 
 func (h *Handler) handleDelTargetLink(link provider.InterfaceLinkDefinition) error {
 	h.Logger.Info("Handling del target link", "link", link)
-	if connections, exists := h.clusterConnections[link.SourceID]; exists {
-		delete(connections, link.Name)
-		if len(connections) == 0 {
-			delete(h.clusterConnections, link.SourceID)
-		}
+	h.queryCursors.closeLink(link.SourceID, link.Name)
+	h.scanCursors.closeLink(link.SourceID, link.Name)
+	h.keyListings.closeLink(link.SourceID, link.Name)
+	h.transactions.rollbackLink(link.SourceID, link.Name)
+	if connection, exists := h.links.remove(link.SourceID, link.Name); exists {
+		h.closeConnection(connection)
+	}
+	return nil
+}
+
+func (h *Handler) handleNewSourceLink(link provider.InterfaceLinkDefinition) error {
+	h.Logger.Info("Handling new source link", "link", link)
+	couchbaseConnectionArgs, err := validateCouchbaseConfig(link.SourceConfig, link.SourceSecrets)
+	if err != nil {
+		h.Logger.Error("Invalid couchbase source config", "error", err)
+		return err
+	}
+	h.subscribeToChanges(link.Target, link.Name, couchbaseConnectionArgs)
+	return nil
+}
+
+func (h *Handler) handleDelSourceLink(link provider.InterfaceLinkDefinition) error {
+	h.Logger.Info("Handling del source link", "link", link)
+	if subscription, exists := h.changeSubscriptions.remove(link.Target, link.Name); exists {
+		h.stopSubscription(link.Target, link.Name, subscription)
 	}
 	return nil
 }
@@ -81,6 +196,18 @@ func (h *Handler) handleHealthCheck() string {
 
 func (h *Handler) handleShutdown() error {
 	h.Logger.Info("Handling shutdown")
-	clear(h.clusterConnections)
+	h.queryCursors.closeAll()
+	h.scanCursors.closeAll()
+	h.keyListings.closeAll()
+	h.transactions.rollbackAll()
+	for _, subscription := range h.changeSubscriptions.removeAll() {
+		h.stopSubscription(subscription.subscription.target, subscription.subscription.linkName, subscription)
+	}
+	for _, connection := range h.links.removeAll() {
+		h.closeConnection(connection)
+	}
+	if err := h.clusters.closeAll(); err != nil {
+		h.Logger.Warn("Error closing cluster connections", "error", err)
+	}
 	return nil
 }
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/query_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
+)
+
+// Name of primary indexes created without a custom name
+const defaultPrimaryIndexName = "#primary"
+
+// GetAllQueryIndexes implements query_index_management.Handler.
+func (h *Handler) GetAllQueryIndexes(ctx context.Context, collection *types.Collection, options *query_index_management.QueryIndexManagementOptions) (*wrpc.Result[[]*query_index_management.QueryIndex, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[[]*query_index_management.QueryIndex](err)
+	}
+	indexes, err := manager.GetAllIndexes(GetAllQueryIndexesOptions(options))
+	if err != nil {
+		h.Logger.Error("Error listing query indexes", "error", err)
+		return wrpc.Err[[]*query_index_management.QueryIndex](*QueryIndexManagementError(err, "")), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](QueryIndexes(indexes)), nil
+}
+
+// CreateQueryIndex implements query_index_management.Handler.
+func (h *Handler) CreateQueryIndex(ctx context.Context, collection *types.Collection, indexName string, keys []string, options *query_index_management.CreateQueryIndexOptions) (*wrpc.Result[struct{}, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[struct{}](err)
+	}
+	if err := manager.CreateIndex(indexName, keys, CreateQueryIndexOptions(options)); err != nil {
+		h.Logger.Error("Error creating query index", "index", indexName, "error", err)
+		return wrpc.Err[struct{}](*QueryIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](struct{}{}), nil
+}
+
+// CreatePrimaryQueryIndex implements query_index_management.Handler.
+func (h *Handler) CreatePrimaryQueryIndex(ctx context.Context, collection *types.Collection, options *query_index_management.CreatePrimaryQueryIndexOptions) (*wrpc.Result[struct{}, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[struct{}](err)
+	}
+	primaryOptions := CreatePrimaryQueryIndexOptions(options)
+	if err := manager.CreatePrimaryIndex(primaryOptions); err != nil {
+		h.Logger.Error("Error creating primary query index", "error", err)
+		return wrpc.Err[struct{}](*QueryIndexManagementError(err, primaryIndexName(primaryOptions.CustomName))), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](struct{}{}), nil
+}
+
+// BuildDeferredQueryIndexes implements query_index_management.Handler.
+func (h *Handler) BuildDeferredQueryIndexes(ctx context.Context, collection *types.Collection, options *query_index_management.QueryIndexManagementOptions) (*wrpc.Result[[]string, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[[]string](err)
+	}
+	indexNames, err := manager.BuildDeferredIndexes(BuildDeferredQueryIndexOptions(options))
+	if err != nil {
+		h.Logger.Error("Error building deferred query indexes", "error", err)
+		return wrpc.Err[[]string](*QueryIndexManagementError(err, "")), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](indexNames), nil
+}
+
+// DropQueryIndex implements query_index_management.Handler.
+func (h *Handler) DropQueryIndex(ctx context.Context, collection *types.Collection, indexName string, options *query_index_management.DropQueryIndexOptions) (*wrpc.Result[struct{}, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[struct{}](err)
+	}
+	if err := manager.DropIndex(indexName, DropQueryIndexOptions(options)); err != nil {
+		h.Logger.Error("Error dropping query index", "index", indexName, "error", err)
+		return wrpc.Err[struct{}](*QueryIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](struct{}{}), nil
+}
+
+// DropPrimaryQueryIndex implements query_index_management.Handler.
+func (h *Handler) DropPrimaryQueryIndex(ctx context.Context, collection *types.Collection, options *query_index_management.DropPrimaryQueryIndexOptions) (*wrpc.Result[struct{}, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[struct{}](err)
+	}
+	primaryOptions := DropPrimaryQueryIndexOptions(options)
+	if err := manager.DropPrimaryIndex(primaryOptions); err != nil {
+		h.Logger.Error("Error dropping primary query index", "error", err)
+		return wrpc.Err[struct{}](*QueryIndexManagementError(err, primaryIndexName(primaryOptions.CustomName))), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](struct{}{}), nil
+}
+
+// WatchQueryIndexes implements query_index_management.Handler.
+func (h *Handler) WatchQueryIndexes(ctx context.Context, collection *types.Collection, indexNames []string, timeoutNs uint64, options *query_index_management.WatchQueryIndexesOptions) (*wrpc.Result[struct{}, query_index_management.QueryIndexManagementError], error) {
+	manager, err := h.getQueryIndexManagerFromContext(ctx, collection)
+	if err != nil {
+		return queryIndexManagerError[struct{}](err)
+	}
+	if err := manager.WatchIndexes(indexNames, time.Duration(timeoutNs), WatchQueryIndexOptions(options)); err != nil {
+		h.Logger.Error("Error watching query indexes", "indexes", indexNames, "error", err)
+		return wrpc.Err[struct{}](*QueryIndexManagementError(err, "")), nil
+	}
+	return wrpc.Ok[query_index_management.QueryIndexManagementError](struct{}{}), nil
+}
+
+// Helper function to get the query index manager of a collection, defaulting to the collection configured
+// on the link the invocation was made on
+func (h *Handler) getQueryIndexManagerFromContext(ctx context.Context, collection *types.Collection) (*gocb.CollectionQueryIndexManager, error) {
+	connection, err := h.getManagementConnectionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keyspace, err := connection.keyspace(collection)
+	if err != nil {
+		return nil, err
+	}
+	return keyspace.QueryIndexes(), nil
+}
+
+// queryIndexManagerError returns the errors getting a query index manager that are caused by the request to the
+// caller, while other errors fail the invocation
+func queryIndexManagerError[T any](err error) (*wrpc.Result[T, query_index_management.QueryIndexManagementError], error) {
+	if errors.Is(err, errBucketNotLinked) {
+		return wrpc.Err[T](*query_index_management.NewQueryIndexManagementErrorInvalidArgument(err.Error())), nil
+	}
+	return managementLinkError[T](err, query_index_management.NewQueryIndexManagementErrorNotAllowed())
+}
+
+func primaryIndexName(customName string) string {
+	if customName == "" {
+		return defaultPrimaryIndexName
+	}
+	return customName
+}
+
+// QueryIndexManagementError maps errors of query index management operations to their query index management error
+func QueryIndexManagementError(err error, indexName string) *query_index_management.QueryIndexManagementError {
+	switch {
+	case errors.Is(err, gocb.ErrIndexNotFound):
+		return query_index_management.NewQueryIndexManagementErrorIndexNotFound(indexName)
+	case errors.Is(err, gocb.ErrIndexExists):
+		return query_index_management.NewQueryIndexManagementErrorIndexExists(indexName)
+	case errors.Is(err, gocb.ErrInvalidArgument), errors.Is(err, gocb.ErrParsingFailure), errors.Is(err, gocb.ErrPlanningFailure):
+		return query_index_management.NewQueryIndexManagementErrorInvalidArgument(err.Error())
+	case errors.Is(err, gocb.ErrTimeout):
+		return query_index_management.NewQueryIndexManagementErrorTimeout()
+	default:
+		return query_index_management.NewQueryIndexManagementErrorUnexpected(err.Error())
+	}
+}
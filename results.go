@@ -1,10 +1,32 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
 	"github.com/couchbase/gocb/v2"
+	"github.com/couchbase/gocbcore/v10"
+	wrpc "wrpc.io/go"
 
 	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/binary"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/collection_management"
 	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/document"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/fts"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/query_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/search_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/sqlpp"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_lookup"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_mutate"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/transactions"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/change_handler"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/sqlpp_types"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
 )
 
 // Result transformers for the document API.
@@ -43,6 +65,31 @@ func GetResult(result *gocb.GetResult) (document.DocumentGetResult, error) {
 	}, nil
 }
 
+func ExistsResult(result *gocb.ExistsResult) document.DocumentExistsResult {
+	return document.DocumentExistsResult{
+		Exists: result.Exists(),
+		Cas:    uint64(result.Cas()),
+	}
+}
+
+// ScanItem converts a document read from a scan, which only has an ID for ids-only scans
+func ScanItem(item *gocb.ScanResultItem) (*document.DocumentScanItem, error) {
+	scanItem := &document.DocumentScanItem{Id: item.ID()}
+	if item.IDOnly() {
+		return scanItem, nil
+	}
+	var content json.RawMessage
+	if err := item.Content(&content); err != nil {
+		return nil, err
+	}
+	scanItem.Document = types.NewDocumentRaw(string(content))
+	scanItem.Cas = uint64(item.Cas())
+	if expiry := item.ExpiryTime(); !expiry.IsZero() {
+		scanItem.ExpiresAt = Time(expiry)
+	}
+	return scanItem, nil
+}
+
 func MutationMetadata(metadata *gocb.MutationResult) document.MutationMetadata {
 	return document.MutationMetadata{
 		Cas:           uint64(metadata.Cas()),
@@ -52,3 +99,612 @@ func MutationMetadata(metadata *gocb.MutationResult) document.MutationMetadata {
 		Seq:           metadata.MutationToken().SequenceNumber(),
 	}
 }
+
+// Result transformers for the binary API.
+
+func CounterResult(result *gocb.CounterResult) binary.CounterResult {
+	metadata := MutationMetadata(&result.MutationResult)
+	return binary.CounterResult{
+		Value:    result.Content(),
+		Metadata: &metadata,
+	}
+}
+
+// Result transformers for the collection-management API.
+
+// ScopeSpecs
+func ScopeSpecs(scopes []gocb.ScopeSpec) []*collection_management.ScopeSpec {
+	specs := make([]*collection_management.ScopeSpec, 0, len(scopes))
+	for _, scope := range scopes {
+		collections := make([]*collection_management.CollectionSpec, 0, len(scope.Collections))
+		for _, collection := range scope.Collections {
+			collections = append(collections, CollectionSpec(collection))
+		}
+		specs = append(specs, &collection_management.ScopeSpec{Name: scope.Name, Collections: collections})
+	}
+	return specs
+}
+
+// CollectionSpec
+func CollectionSpec(collection gocb.CollectionSpec) *collection_management.CollectionSpec {
+	spec := &collection_management.CollectionSpec{
+		Name:      collection.Name,
+		ScopeName: collection.ScopeName,
+		MaxExpiry: MaxExpiry(collection.MaxExpiry),
+	}
+	if collection.History != nil {
+		spec.History = &collection.History.Enabled
+	}
+	return spec
+}
+
+// MaxExpiry converts the maximum expiry of a collection, 0 standing for the bucket default and negative values for no expiry
+func MaxExpiry(maxExpiry time.Duration) *collection_management.CollectionMaxExpiry {
+	switch {
+	case maxExpiry == 0:
+		return collection_management.NewCollectionMaxExpiryBucketDefault()
+	case maxExpiry < 0:
+		return collection_management.NewCollectionMaxExpiryNoExpiry()
+	default:
+		return collection_management.NewCollectionMaxExpiryExpiresInNs(uint64(maxExpiry))
+	}
+}
+
+// Result transformers for the query-index-management API.
+
+// QueryIndexes
+func QueryIndexes(indexes []gocb.QueryIndex) []*query_index_management.QueryIndex {
+	results := make([]*query_index_management.QueryIndex, 0, len(indexes))
+	for _, index := range indexes {
+		results = append(results, QueryIndex(index))
+	}
+	return results
+}
+
+// QueryIndex
+func QueryIndex(index gocb.QueryIndex) *query_index_management.QueryIndex {
+	result := &query_index_management.QueryIndex{
+		Name:           index.Name,
+		IsPrimary:      index.IsPrimary,
+		IndexType:      string(index.Type),
+		State:          index.State,
+		BucketName:     index.BucketName,
+		ScopeName:      index.ScopeName,
+		CollectionName: index.CollectionName,
+		IndexKey:       index.IndexKey,
+	}
+	if index.Condition != "" {
+		result.Condition = &index.Condition
+	}
+	if index.Partition != "" {
+		result.Partition = &index.Partition
+	}
+	return result
+}
+
+// Result transformers for the search-index-management API.
+
+// SearchIndex
+func SearchIndex(index gocb.SearchIndex) *search_index_management.SearchIndex {
+	result := &search_index_management.SearchIndex{
+		Name:       index.Name,
+		Uuid:       index.UUID,
+		IndexType:  index.Type,
+		SourceName: index.SourceName,
+		SourceType: index.SourceType,
+		SourceUuid: index.SourceUUID,
+	}
+	if index.Params != nil {
+		result.Params = optionalJSON(index.Params)
+	}
+	if index.SourceParams != nil {
+		result.SourceParams = optionalJSON(index.SourceParams)
+	}
+	if index.PlanParams != nil {
+		result.PlanParams = optionalJSON(index.PlanParams)
+	}
+	return result
+}
+
+// Result transformers for the subdocument-lookup API.
+
+func LookupInResult(result *gocb.LookupInResult, operations []*subdocument_lookup.LookupOperation) LookupInResults {
+	lookupResults := make(LookupInResults, 0, len(operations))
+	for idx, op := range operations {
+		doc, err := lookupInSpecResult(result, uint(idx), op)
+		if err != nil {
+			lookupResults = append(lookupResults, wrpc.Err[subdocument_lookup.LookupInDocument](*err))
+			continue
+		}
+		lookupResults = append(lookupResults, wrpc.Ok[subdocument_lookup.SubdocumentLookupError](subdocument_lookup.LookupInDocument{
+			Cas:      uint64(result.Cas()),
+			Document: doc,
+		}))
+	}
+	return lookupResults
+}
+
+func LookupInReplicaResult(result *gocb.LookupInReplicaResult, operations []*subdocument_lookup.LookupOperation) LookupInReplicaResults {
+	lookupResults := make(LookupInReplicaResults, 0, len(operations))
+	for idx, op := range operations {
+		doc, err := lookupInSpecResult(result.LookupInResult, uint(idx), op)
+		if err != nil {
+			lookupResults = append(lookupResults, wrpc.Err[subdocument_lookup.LookupInReplicaDocument](*err))
+			continue
+		}
+		lookupResults = append(lookupResults, wrpc.Ok[subdocument_lookup.SubdocumentLookupError](subdocument_lookup.LookupInReplicaDocument{
+			Cas:       uint64(result.Cas()),
+			IsReplica: result.IsReplica(),
+			Document:  doc,
+		}))
+	}
+	return lookupResults
+}
+
+func LookupInAllReplicasResult(result *gocb.LookupInAllReplicasResult, operations []*subdocument_lookup.LookupOperation) []LookupInReplicaResults {
+	defer result.Close()
+	var replicaResults []LookupInReplicaResults
+	next := result.Next()
+	for next != nil {
+		replicaResults = append(replicaResults, LookupInReplicaResult(next, operations))
+		next = result.Next()
+	}
+	return replicaResults
+}
+
+// lookupInSpecResult converts the value of a single lookup spec into a document, which contains
+// a JSON boolean for exists operations and the raw JSON value at the path otherwise.
+func lookupInSpecResult(result *gocb.LookupInResult, idx uint, op *subdocument_lookup.LookupOperation) (*types.Document, *subdocument_lookup.SubdocumentLookupError) {
+	if op.Discriminant() == subdocument_lookup.LookupOperationExists {
+		return types.NewDocumentRaw(strconv.FormatBool(result.Exists(idx))), nil
+	}
+	var content json.RawMessage
+	if err := result.ContentAt(idx, &content); err != nil {
+		path := lookupOperationPath(op)
+		switch {
+		case errors.Is(err, gocb.ErrPathNotFound):
+			return nil, subdocument_lookup.NewSubdocumentLookupErrorPathDoesNotExist(path)
+		case errors.Is(err, gocb.ErrPathMismatch):
+			return nil, subdocument_lookup.NewSubdocumentLookupErrorPathMismatch(path)
+		default:
+			return nil, subdocument_lookup.NewSubdocumentLookupErrorUnexpected(err.Error())
+		}
+	}
+	return types.NewDocumentRaw(string(content)), nil
+}
+
+func lookupOperationPath(op *subdocument_lookup.LookupOperation) string {
+	if payload, ok := op.GetExists(); ok {
+		return payload.V0
+	}
+	if payload, ok := op.GetGet(); ok {
+		return payload.V0
+	}
+	if payload, ok := op.GetCount(); ok {
+		return payload.V0
+	}
+	return ""
+}
+
+// Result transformers for the subdocument-mutate API.
+
+func MutateInResult(result *gocb.MutateInResult, operations []*subdocument_mutate.MutateOperation) MutateInResults {
+	metadata := SubdocumentMutationMetadata(result)
+	mutateResults := make(MutateInResults, 0, len(operations))
+	for idx, op := range operations {
+		mutationResult := subdocument_mutate.MutationResult{Metadata: &metadata}
+		switch op.Discriminant() {
+		case subdocument_mutate.MutateOperationIncrement, subdocument_mutate.MutateOperationDecrement:
+			var content json.RawMessage
+			if err := result.ContentAt(uint(idx), &content); err == nil {
+				mutationResult.Document = types.NewDocumentRaw(string(content))
+			}
+		}
+		mutateResults = append(mutateResults, wrpc.Ok[subdocument_mutate.SubdocumentMutateError](mutationResult))
+	}
+	return mutateResults
+}
+
+// MutateInSpecErrors reports the failure of a single spec, marking all other specs as not applied
+// since mutations are performed atomically.
+func MutateInSpecErrors(subdocErr gocbcore.SubDocumentError, operations []*subdocument_mutate.MutateOperation) MutateInResults {
+	mutateResults := make(MutateInResults, 0, len(operations))
+	for idx, op := range operations {
+		if idx != subdocErr.Index {
+			mutateResults = append(mutateResults, wrpc.Err[subdocument_mutate.MutationResult](*subdocument_mutate.NewSubdocumentMutateErrorNotApplied()))
+			continue
+		}
+		path := mutateOperationPath(op)
+		var specErr *subdocument_mutate.SubdocumentMutateError
+		switch {
+		case errors.Is(subdocErr, gocb.ErrPathExists):
+			specErr = subdocument_mutate.NewSubdocumentMutateErrorPathAlreadyExists(path)
+		case errors.Is(subdocErr, gocb.ErrPathNotFound):
+			specErr = subdocument_mutate.NewSubdocumentMutateErrorPathDoesNotExist(path)
+		case errors.Is(subdocErr, gocb.ErrPathMismatch):
+			specErr = subdocument_mutate.NewSubdocumentMutateErrorPathMismatch(path)
+		default:
+			specErr = subdocument_mutate.NewSubdocumentMutateErrorUnexpected(subdocErr.Error())
+		}
+		mutateResults = append(mutateResults, wrpc.Err[subdocument_mutate.MutationResult](*specErr))
+	}
+	return mutateResults
+}
+
+func SubdocumentMutationMetadata(result *gocb.MutateInResult) subdocument_mutate.MutationMetadata {
+	metadata := subdocument_mutate.MutationMetadata{
+		Cas: uint64(result.Cas()),
+	}
+	if token := result.MutationToken(); token != nil {
+		metadata.Bucket = token.BucketName()
+		metadata.PartitionId = token.PartitionID()
+		metadata.PartitionUuid = token.PartitionUUID()
+		metadata.Seq = token.SequenceNumber()
+	}
+	return metadata
+}
+
+func mutateOperationPath(op *subdocument_mutate.MutateOperation) string {
+	switch op.Discriminant() {
+	case subdocument_mutate.MutateOperationInsert:
+		payload, _ := op.GetInsert()
+		return payload.V0
+	case subdocument_mutate.MutateOperationUpsert:
+		payload, _ := op.GetUpsert()
+		return payload.V0
+	case subdocument_mutate.MutateOperationReplace:
+		payload, _ := op.GetReplace()
+		return payload.V0
+	case subdocument_mutate.MutateOperationRemove:
+		payload, _ := op.GetRemove()
+		return payload.V0
+	case subdocument_mutate.MutateOperationIncrement:
+		payload, _ := op.GetIncrement()
+		return payload.V0
+	case subdocument_mutate.MutateOperationDecrement:
+		payload, _ := op.GetDecrement()
+		return payload.V0
+	case subdocument_mutate.MutateOperationArrayAddUnique:
+		payload, _ := op.GetArrayAddUnique()
+		return payload.V0
+	case subdocument_mutate.MutateOperationArrayAppend:
+		payload, _ := op.GetArrayAppend()
+		return payload.V0.V0
+	case subdocument_mutate.MutateOperationArrayAppendMulti:
+		payload, _ := op.GetArrayAppendMulti()
+		return payload.V0
+	case subdocument_mutate.MutateOperationArrayInsert:
+		payload, _ := op.GetArrayInsert()
+		return payload.V0.V0
+	case subdocument_mutate.MutateOperationArrayInsertMulti:
+		payload, _ := op.GetArrayInsertMulti()
+		return payload.V0
+	case subdocument_mutate.MutateOperationArrayPrepend:
+		payload, _ := op.GetArrayPrepend()
+		return payload.V0.V0
+	case subdocument_mutate.MutateOperationArrayPrependMulti:
+		payload, _ := op.GetArrayPrependMulti()
+		return payload.V0
+	default:
+		return ""
+	}
+}
+
+// Result transformers for the sqlpp API.
+
+// SqlppValues converts the rows of a query to SQL++ values
+func SqlppValues(rows []json.RawMessage) ([]*sqlpp.SqlppValue, error) {
+	values := make([]*sqlpp.SqlppValue, 0, len(rows))
+	for _, row := range rows {
+		value, err := SqlppValue(row)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// SqlppValue converts a JSON value to its SQL++ value, keeping arrays and objects as JSON
+func SqlppValue(raw json.RawMessage) (*sqlpp.SqlppValue, error) {
+	trimmed := bytes.TrimSpace(raw)
+	if !json.Valid(trimmed) {
+		return nil, errors.New("invalid JSON value")
+	}
+	switch trimmed[0] {
+	case 'n':
+		return sqlpp_types.NewSqlppValueNull(), nil
+	case 't', 'f':
+		var value bool
+		if err := json.Unmarshal(trimmed, &value); err != nil {
+			return nil, err
+		}
+		return sqlpp_types.NewSqlppValueBoolean(value), nil
+	case '"':
+		var value string
+		if err := json.Unmarshal(trimmed, &value); err != nil {
+			return nil, err
+		}
+		return sqlpp_types.NewSqlppValueString(value), nil
+	case '[':
+		return sqlpp_types.NewSqlppValueArray(string(trimmed)), nil
+	case '{':
+		return sqlpp_types.NewSqlppValueObject(string(trimmed)), nil
+	default:
+		if value, err := strconv.ParseInt(string(trimmed), 10, 64); err == nil {
+			return sqlpp_types.NewSqlppValueInteger(value), nil
+		}
+		value, err := strconv.ParseFloat(string(trimmed), 64)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported JSON number %s", trimmed)
+		}
+		return sqlpp_types.NewSqlppValueFloat(value), nil
+	}
+}
+
+// SqlppQueryMetadata
+func SqlppQueryMetadata(metadata *gocb.QueryMetaData) sqlpp.SqlppQueryMetadata {
+	warnings := make([]*sqlpp.SqlppQueryWarning, 0, len(metadata.Warnings))
+	for _, warning := range metadata.Warnings {
+		warnings = append(warnings, &sqlpp.SqlppQueryWarning{Code: warning.Code, Message: warning.Message})
+	}
+	result := sqlpp.SqlppQueryMetadata{
+		RequestId:       metadata.RequestID,
+		ClientContextId: metadata.ClientContextID,
+		Status:          SqlppQueryStatus(metadata.Status),
+		Warnings:        warnings,
+		Profile:         optionalJSON(metadata.Profile),
+		Signature:       optionalJSON(metadata.Signature),
+	}
+	// Metrics are only included in the response when they were requested
+	if metadata.Metrics != (gocb.QueryMetrics{}) {
+		result.Metrics = &sqlpp.SqlppQueryMetrics{
+			ElapsedTimeNs:   uint64(metadata.Metrics.ElapsedTime),
+			ExecutionTimeNs: uint64(metadata.Metrics.ExecutionTime),
+			ResultCount:     metadata.Metrics.ResultCount,
+			ResultSize:      metadata.Metrics.ResultSize,
+			MutationCount:   metadata.Metrics.MutationCount,
+			SortCount:       metadata.Metrics.SortCount,
+			ErrorCount:      metadata.Metrics.ErrorCount,
+			WarningCount:    metadata.Metrics.WarningCount,
+		}
+	}
+	return result
+}
+
+// SqlppQueryStatus
+func SqlppQueryStatus(status gocb.QueryStatus) sqlpp.SqlppQueryStatus {
+	switch status {
+	case gocb.QueryStatusRunning:
+		return sqlpp.SqlppQueryStatus_Running
+	case gocb.QueryStatusSuccess:
+		return sqlpp.SqlppQueryStatus_Success
+	case gocb.QueryStatusErrors:
+		return sqlpp.SqlppQueryStatus_Errors
+	case gocb.QueryStatusCompleted:
+		return sqlpp.SqlppQueryStatus_Completed
+	case gocb.QueryStatusStopped:
+		return sqlpp.SqlppQueryStatus_Stopped
+	case gocb.QueryStatusTimeout:
+		return sqlpp.SqlppQueryStatus_Timeout
+	case gocb.QueryStatusClosed:
+		return sqlpp.SqlppQueryStatus_Closed
+	case gocb.QueryStatusFatal:
+		return sqlpp.SqlppQueryStatus_Fatal
+	case gocb.QueryStatusAborted:
+		return sqlpp.SqlppQueryStatus_Aborted
+	default:
+		return sqlpp.SqlppQueryStatus_Unknown
+	}
+}
+
+// optionalJSON encodes a value that was decoded from a response, if present
+func optionalJSON(value interface{}) *string {
+	if value == nil {
+		return nil
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	result := string(encoded)
+	return &result
+}
+
+// Result transformers for the fts API.
+
+// SearchResult collects the hits, metadata and facets of a search
+func SearchResult(result *gocb.SearchResult) (fts.SearchResult, error) {
+	rows := []*fts.SearchRow{}
+	for result.Next() {
+		rows = append(rows, SearchRow(result.Row()))
+	}
+	if err := result.Err(); err != nil {
+		return fts.SearchResult{}, err
+	}
+	metadata, err := result.MetaData()
+	if err != nil {
+		return fts.SearchResult{}, err
+	}
+	facets, err := result.Facets()
+	if err != nil {
+		return fts.SearchResult{}, err
+	}
+	if err := result.Close(); err != nil {
+		return fts.SearchResult{}, err
+	}
+	return fts.SearchResult{
+		Rows:     rows,
+		Metadata: SearchMetadata(metadata),
+		Facets:   SearchFacetResults(facets),
+	}, nil
+}
+
+// SearchRow
+func SearchRow(row gocb.SearchRow) *fts.SearchRow {
+	searchRow := &fts.SearchRow{
+		Index:       row.Index,
+		Id:          row.ID,
+		Score:       row.Score,
+		Explanation: optionalJSON(row.Explanation),
+		Locations:   []*fts.SearchRowLocation{},
+		Fragments:   []*wrpc.Tuple2[string, []string]{},
+	}
+	for _, field := range sortedKeys(row.Locations) {
+		for _, term := range sortedKeys(row.Locations[field]) {
+			for _, location := range row.Locations[field][term] {
+				searchRow.Locations = append(searchRow.Locations, &fts.SearchRowLocation{
+					Field:          field,
+					Term:           term,
+					Position:       location.Position,
+					Start:          location.Start,
+					End:            location.End,
+					ArrayPositions: location.ArrayPositions,
+				})
+			}
+		}
+	}
+	for _, field := range sortedKeys(row.Fragments) {
+		searchRow.Fragments = append(searchRow.Fragments, &wrpc.Tuple2[string, []string]{V0: field, V1: row.Fragments[field]})
+	}
+	// Hits only carry fields when they were requested and are stored in the index
+	var fields json.RawMessage
+	if err := row.Fields(&fields); err == nil && len(fields) > 0 {
+		searchRow.Fields = types.NewDocumentRaw(string(fields))
+	}
+	return searchRow
+}
+
+// SearchMetadata
+func SearchMetadata(metadata *gocb.SearchMetaData) *fts.SearchMetadata {
+	partitionErrors := []*wrpc.Tuple2[string, string]{}
+	for _, partition := range sortedKeys(metadata.Errors) {
+		partitionErrors = append(partitionErrors, &wrpc.Tuple2[string, string]{V0: partition, V1: metadata.Errors[partition]})
+	}
+	return &fts.SearchMetadata{
+		TotalHits:             metadata.Metrics.TotalRows,
+		MaxScore:              metadata.Metrics.MaxScore,
+		TookNs:                uint64(metadata.Metrics.Took),
+		TotalPartitionCount:   metadata.Metrics.TotalPartitionCount,
+		SuccessPartitionCount: metadata.Metrics.SuccessPartitionCount,
+		ErrorPartitionCount:   metadata.Metrics.ErrorPartitionCount,
+		Errors:                partitionErrors,
+	}
+}
+
+// SearchFacetResults
+func SearchFacetResults(facets map[string]gocb.SearchFacetResult) []*fts.SearchFacetResult {
+	results := make([]*fts.SearchFacetResult, 0, len(facets))
+	for _, name := range sortedKeys(facets) {
+		facet := facets[name]
+		result := &fts.SearchFacetResult{
+			Name:          name,
+			Field:         facet.Field,
+			Total:         facet.Total,
+			Missing:       facet.Missing,
+			Other:         facet.Other,
+			Terms:         make([]*fts.SearchTermFacetResult, 0, len(facet.Terms)),
+			NumericRanges: make([]*fts.SearchNumericRangeFacetResult, 0, len(facet.NumericRanges)),
+			DateRanges:    make([]*fts.SearchDateRangeFacetResult, 0, len(facet.DateRanges)),
+		}
+		for _, term := range facet.Terms {
+			result.Terms = append(result.Terms, &fts.SearchTermFacetResult{Term: term.Term, Count: uint64(term.Count)})
+		}
+		for _, r := range facet.NumericRanges {
+			result.NumericRanges = append(result.NumericRanges, &fts.SearchNumericRangeFacetResult{Name: r.Name, Min: r.Min, Max: r.Max, Count: uint64(r.Count)})
+		}
+		for _, r := range facet.DateRanges {
+			result.DateRanges = append(result.DateRanges, &fts.SearchDateRangeFacetResult{Name: r.Name, Start: r.Start, End: r.End, Count: uint64(r.Count)})
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// sortedKeys returns the keys of a map in order, so that results are deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Result transformers for the transactions API.
+
+// TxDocumentGetResult
+func TxDocumentGetResult(doc *gocb.TransactionGetResult, metadataId string) (transactions.TxDocumentGetResult, error) {
+	var content json.RawMessage
+	if err := doc.Content(&content); err != nil {
+		return transactions.TxDocumentGetResult{}, err
+	}
+	return transactions.TxDocumentGetResult{
+		Document:     types.NewDocumentRaw(string(content)),
+		TxMetadataId: metadataId,
+	}, nil
+}
+
+// TxSqlppQueryMetadata
+func TxSqlppQueryMetadata(metadata *gocb.QueryMetaData) transactions.TxSqlppQueryMetadata {
+	converted := SqlppQueryMetadata(metadata)
+	metrics := converted.Metrics
+	if metrics == nil {
+		metrics = &sqlpp.SqlppQueryMetrics{}
+	}
+	return transactions.TxSqlppQueryMetadata{
+		RequestId:       converted.RequestId,
+		ClientContextId: converted.ClientContextId,
+		Status:          converted.Status,
+		Metrics:         metrics,
+		Warnings:        converted.Warnings,
+	}
+}
+
+// TxResult
+func TxResult(result *gocb.TransactionResult) transactions.TxResult {
+	return transactions.TxResult{
+		Id:                result.TransactionID,
+		UnstagingComplete: result.UnstagingComplete,
+	}
+}
+
+// Result transformers for the change-handler API.
+
+// DocumentChange
+func DocumentChange(change documentChange) *change_handler.DocumentChange {
+	converted := &change_handler.DocumentChange{
+		Id:          change.id,
+		Cas:         change.cas,
+		PartitionId: change.partition,
+		Seq:         change.position.Seq,
+	}
+	switch change.kind {
+	case changeMutation:
+		converted.Kind = change_handler.DocumentChangeKind_Mutation
+		// Documents that are not JSON (ex. binary values) are only identified
+		if json.Valid(change.value) {
+			converted.Document = types.NewDocumentRaw(string(change.value))
+		}
+	case changeDeletion:
+		converted.Kind = change_handler.DocumentChangeKind_Deletion
+	case changeExpiration:
+		converted.Kind = change_handler.DocumentChangeKind_Expiration
+	}
+	return converted
+}
+
+// Time converts a point in time to UTC
+func Time(t time.Time) *types.Time {
+	t = t.UTC()
+	return &types.Time{
+		Year:         int32(t.Year()),
+		Month:        uint8(t.Month()),
+		Day:          uint8(t.Day()),
+		Hour:         uint8(t.Hour()),
+		Minute:       uint8(t.Minute()),
+		Second:       uint8(t.Second()),
+		Milliseconds: uint32(t.Nanosecond() / int(time.Millisecond)),
+		Nanoseconds:  uint32(t.Nanosecond() % int(time.Millisecond)),
+	}
+}
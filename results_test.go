@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/collection_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/sqlpp"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/sqlpp_types"
+)
+
+func TestSqlppValue(t *testing.T) {
+	tests := []struct {
+		raw          string
+		discriminant sqlpp_types.SqlppValueDiscriminant
+		check        func(*sqlpp.SqlppValue) bool
+	}{
+		{`null`, sqlpp_types.SqlppValueNull, nil},
+		{`true`, sqlpp_types.SqlppValueBoolean, func(v *sqlpp.SqlppValue) bool { b, _ := v.GetBoolean(); return b }},
+		{`-42`, sqlpp_types.SqlppValueInteger, func(v *sqlpp.SqlppValue) bool { i, _ := v.GetInteger(); return i == -42 }},
+		{`1.5e3`, sqlpp_types.SqlppValueFloat, func(v *sqlpp.SqlppValue) bool { f, _ := v.GetFloat(); return f == 1500 }},
+		{`"hotel"`, sqlpp_types.SqlppValueString, func(v *sqlpp.SqlppValue) bool { s, _ := v.GetString(); return s == "hotel" }},
+		{` [1, 2] `, sqlpp_types.SqlppValueArray, func(v *sqlpp.SqlppValue) bool { a, _ := v.GetArray(); return a == `[1, 2]` }},
+		{`{"id": 1}`, sqlpp_types.SqlppValueObject, func(v *sqlpp.SqlppValue) bool { o, _ := v.GetObject(); return o == `{"id": 1}` }},
+	}
+
+	for _, test := range tests {
+		value, err := SqlppValue(json.RawMessage(test.raw))
+		if err != nil {
+			t.Errorf("did not expect error for '%s', got %v", test.raw, err)
+			continue
+		}
+		if value.Discriminant() != test.discriminant {
+			t.Errorf("expected %v for '%s', got %v", test.discriminant, test.raw, value.Discriminant())
+			continue
+		}
+		if test.check != nil && !test.check(value) {
+			t.Errorf("unexpected payload for '%s'", test.raw)
+		}
+	}
+
+	if _, err := SqlppValue(json.RawMessage(`nope`)); err == nil {
+		t.Errorf("expected error for invalid JSON, got none")
+	}
+}
+
+func TestMaxExpiry(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxExpiry    time.Duration
+		discriminant collection_management.CollectionMaxExpiryDiscriminant
+	}{
+		{"bucket default", 0, collection_management.CollectionMaxExpiryBucketDefault},
+		{"no expiry", -time.Second, collection_management.CollectionMaxExpiryNoExpiry},
+		{"expiry", time.Hour, collection_management.CollectionMaxExpiryExpiresInNs},
+	}
+
+	for _, test := range tests {
+		maxExpiry := MaxExpiry(test.maxExpiry)
+		if maxExpiry.Discriminant() != test.discriminant {
+			t.Errorf("expected %v for %s max expiry, got %v", test.discriminant, test.name, maxExpiry.Discriminant())
+		}
+		// Converting back must give the same max expiry, so that specs can be used as settings
+		if actual := CollectionMaxExpiry(maxExpiry); actual != test.maxExpiry {
+			t.Errorf("expected %s max expiry to convert back to %v, got %v", test.name, test.maxExpiry, actual)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/document"
+)
+
+// An open KV scan whose documents are streamed to a component in batches
+type scanCursor struct {
+	cursorOwner
+	result *gocb.ScanResult
+	done   bool
+}
+
+type scanCursors = cursors[*scanCursor]
+
+func newScanCursors() *scanCursors {
+	return &scanCursors{cursors: make(map[string]*scanCursor), notFound: errScanCursorNotFound}
+}
+
+func newScanCursor(sourceId, linkName string, result *gocb.ScanResult, idleTimeout time.Duration) *scanCursor {
+	return &scanCursor{
+		cursorOwner: newCursorOwner(sourceId, linkName, idleTimeout),
+		result:      result,
+	}
+}
+
+// next reads up to max documents from the scan, reporting whether all documents have been read
+func (s *scanCursor) next(max uint32) ([]*gocb.ScanResultItem, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, true, errScanCursorNotFound
+	}
+	s.lastUsed = time.Now()
+
+	max = min(max, cursorBatchLimit)
+	items := make([]*gocb.ScanResultItem, 0, max)
+	for !s.done && uint32(len(items)) < max {
+		item := s.result.Next()
+		if item == nil {
+			s.done = true
+			if err := s.result.Err(); err != nil {
+				return nil, true, err
+			}
+			break
+		}
+		items = append(items, item)
+	}
+	return items, s.done, nil
+}
+
+func (s *scanCursor) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return s.result.Close()
+}
+
+// Scan implements document.Handler.
+func (h *Handler) Scan(ctx context.Context, scanType *document.DocumentScanType, options *document.DocumentScanOptions) (*wrpc.Result[document.DocumentScanHandle, document.DocumentScanError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	scan, err := ScanType(scanType)
+	if err != nil {
+		return wrpc.Err[document.DocumentScanHandle](*document.NewDocumentScanErrorInvalidArgument(err.Error())), nil
+	}
+
+	result, err := connection.Collection.Scan(scan, ScanOptions(options))
+	if err != nil {
+		h.Logger.Error("Error starting scan", "error", err)
+		return wrpc.Err[document.DocumentScanHandle](*DocumentScanError(err)), nil
+	}
+	handle := h.scanCursors.open(newScanCursor(sourceId, linkName, result, connection.CursorIdleTimeout))
+	return wrpc.Ok[document.DocumentScanError](handle), nil
+}
+
+// ScanNext implements document.Handler.
+func (h *Handler) ScanNext(ctx context.Context, handle string, maxItems uint32) (*wrpc.Result[document.DocumentScanBatch, document.DocumentScanError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := h.scanCursors.get(sourceId, linkName, handle)
+	if err != nil {
+		return wrpc.Err[document.DocumentScanBatch](*document.NewDocumentScanErrorInvalidHandle(handle)), nil
+	}
+	results, done, err := cursor.next(maxItems)
+	if errors.Is(err, errScanCursorNotFound) {
+		return wrpc.Err[document.DocumentScanBatch](*document.NewDocumentScanErrorInvalidHandle(handle)), nil
+	}
+	if err != nil {
+		h.Logger.Error("Error reading scan documents", "error", err)
+		return wrpc.Err[document.DocumentScanBatch](*DocumentScanError(err)), nil
+	}
+	items := make([]*document.DocumentScanItem, 0, len(results))
+	for _, result := range results {
+		item, err := ScanItem(result)
+		if err != nil {
+			h.Logger.Error("Error converting scan document", "id", result.ID(), "error", err)
+			return wrpc.Err[document.DocumentScanBatch](*document.NewDocumentScanErrorUnexpected(err.Error())), nil
+		}
+		items = append(items, item)
+	}
+	return wrpc.Ok[document.DocumentScanError](document.DocumentScanBatch{Items: items, Done: done}), nil
+}
+
+// ScanClose implements document.Handler.
+func (h *Handler) ScanClose(ctx context.Context, handle string) (*wrpc.Result[struct{}, document.DocumentScanError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.scanCursors.close(sourceId, linkName, handle); err != nil {
+		if errors.Is(err, errScanCursorNotFound) {
+			return wrpc.Err[struct{}](*document.NewDocumentScanErrorInvalidHandle(handle)), nil
+		}
+		// The cursor is removed regardless, the error only concerns the remainder of the stream
+		h.Logger.Warn("Error closing scan", "error", err)
+	}
+	return wrpc.Ok[document.DocumentScanError](struct{}{}), nil
+}
+
+// DocumentScanError maps errors returned by a KV scan to their scan error
+func DocumentScanError(err error) *document.DocumentScanError {
+	switch {
+	case errors.Is(err, gocb.ErrInvalidArgument), errors.Is(err, gocb.ErrFeatureNotAvailable):
+		return document.NewDocumentScanErrorInvalidArgument(err.Error())
+	case errors.Is(err, gocb.ErrTimeout):
+		return document.NewDocumentScanErrorTimeout()
+	default:
+		return document.NewDocumentScanErrorUnexpected(err.Error())
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/search_index_management"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
+)
+
+// Search index operations shared by the cluster and scope level search index managers
+type searchIndexManager interface {
+	GetAllIndexes(opts *gocb.GetAllSearchIndexOptions) ([]gocb.SearchIndex, error)
+	GetIndex(indexName string, opts *gocb.GetSearchIndexOptions) (*gocb.SearchIndex, error)
+	UpsertIndex(indexDefinition gocb.SearchIndex, opts *gocb.UpsertSearchIndexOptions) error
+	DropIndex(indexName string, opts *gocb.DropSearchIndexOptions) error
+	AnalyzeDocument(indexName string, doc interface{}, opts *gocb.AnalyzeDocumentOptions) ([]interface{}, error)
+	GetIndexedDocumentsCount(indexName string, opts *gocb.GetIndexedDocumentsCountOptions) (uint64, error)
+	PauseIngest(indexName string, opts *gocb.PauseIngestSearchIndexOptions) error
+	ResumeIngest(indexName string, opts *gocb.ResumeIngestSearchIndexOptions) error
+}
+
+// GetAllSearchIndexes implements search_index_management.Handler.
+func (h *Handler) GetAllSearchIndexes(ctx context.Context, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[[]*search_index_management.SearchIndex, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[[]*search_index_management.SearchIndex](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	indexes, err := manager.GetAllIndexes(GetAllSearchIndexOptions(options))
+	if err != nil {
+		h.Logger.Error("Error listing search indexes", "error", err)
+		return wrpc.Err[[]*search_index_management.SearchIndex](*SearchIndexManagementError(err, "")), nil
+	}
+	results := make([]*search_index_management.SearchIndex, 0, len(indexes))
+	for _, index := range indexes {
+		results = append(results, SearchIndex(index))
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](results), nil
+}
+
+// GetSearchIndex implements search_index_management.Handler.
+func (h *Handler) GetSearchIndex(ctx context.Context, indexName string, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[search_index_management.SearchIndex, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[search_index_management.SearchIndex](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	index, err := manager.GetIndex(indexName, GetSearchIndexOptions(options))
+	if err != nil {
+		h.Logger.Error("Error getting search index", "index", indexName, "error", err)
+		return wrpc.Err[search_index_management.SearchIndex](*SearchIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](*SearchIndex(*index)), nil
+}
+
+// UpsertSearchIndex implements search_index_management.Handler.
+func (h *Handler) UpsertSearchIndex(ctx context.Context, index *search_index_management.SearchIndex, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[struct{}, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	definition, err := SearchIndexDefinition(index)
+	if err != nil {
+		return wrpc.Err[struct{}](*search_index_management.NewSearchIndexManagementErrorInvalidArgument(err.Error())), nil
+	}
+	if err := manager.UpsertIndex(definition, UpsertSearchIndexOptions(options)); err != nil {
+		h.Logger.Error("Error upserting search index", "index", index.Name, "error", err)
+		return wrpc.Err[struct{}](*SearchIndexManagementError(err, index.Name)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](struct{}{}), nil
+}
+
+// DropSearchIndex implements search_index_management.Handler.
+func (h *Handler) DropSearchIndex(ctx context.Context, indexName string, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[struct{}, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	if err := manager.DropIndex(indexName, DropSearchIndexOptions(options)); err != nil {
+		h.Logger.Error("Error dropping search index", "index", indexName, "error", err)
+		return wrpc.Err[struct{}](*SearchIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](struct{}{}), nil
+}
+
+// PauseSearchIndexIngest implements search_index_management.Handler.
+func (h *Handler) PauseSearchIndexIngest(ctx context.Context, indexName string, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[struct{}, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	if err := manager.PauseIngest(indexName, PauseIngestSearchIndexOptions(options)); err != nil {
+		h.Logger.Error("Error pausing search index ingestion", "index", indexName, "error", err)
+		return wrpc.Err[struct{}](*SearchIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](struct{}{}), nil
+}
+
+// ResumeSearchIndexIngest implements search_index_management.Handler.
+func (h *Handler) ResumeSearchIndexIngest(ctx context.Context, indexName string, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[struct{}, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[struct{}](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	if err := manager.ResumeIngest(indexName, ResumeIngestSearchIndexOptions(options)); err != nil {
+		h.Logger.Error("Error resuming search index ingestion", "index", indexName, "error", err)
+		return wrpc.Err[struct{}](*SearchIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](struct{}{}), nil
+}
+
+// AnalyzeSearchDocument implements search_index_management.Handler.
+func (h *Handler) AnalyzeSearchDocument(ctx context.Context, indexName string, doc *types.Document, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[[]string, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[[]string](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	content, err := DocumentJSON(doc)
+	if err != nil {
+		return wrpc.Err[[]string](*search_index_management.NewSearchIndexManagementErrorInvalidArgument(err.Error())), nil
+	}
+	analysis, err := manager.AnalyzeDocument(indexName, content, AnalyzeDocumentOptions(options))
+	if err != nil {
+		h.Logger.Error("Error analyzing document", "index", indexName, "error", err)
+		return wrpc.Err[[]string](*SearchIndexManagementError(err, indexName)), nil
+	}
+	results := make([]string, 0, len(analysis))
+	for _, result := range analysis {
+		if encoded := optionalJSON(result); encoded != nil {
+			results = append(results, *encoded)
+		}
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](results), nil
+}
+
+// GetSearchIndexedDocumentsCount implements search_index_management.Handler.
+func (h *Handler) GetSearchIndexedDocumentsCount(ctx context.Context, indexName string, options *search_index_management.SearchIndexManagementOptions) (*wrpc.Result[uint64, search_index_management.SearchIndexManagementError], error) {
+	manager, err := h.getSearchIndexManagerFromContext(ctx)
+	if err != nil {
+		return managementLinkError[uint64](err, search_index_management.NewSearchIndexManagementErrorNotAllowed())
+	}
+	count, err := manager.GetIndexedDocumentsCount(indexName, GetIndexedDocumentsCountOptions(options))
+	if err != nil {
+		h.Logger.Error("Error counting indexed documents", "index", indexName, "error", err)
+		return wrpc.Err[uint64](*SearchIndexManagementError(err, indexName)), nil
+	}
+	return wrpc.Ok[search_index_management.SearchIndexManagementError](count), nil
+}
+
+// Helper function to get the search index manager of the scope configured on the link the invocation was made on,
+// falling back to the cluster when the link uses the default scope
+func (h *Handler) getSearchIndexManagerFromContext(ctx context.Context) (searchIndexManager, error) {
+	connection, err := h.getManagementConnectionFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if scopeName := connection.Collection.ScopeName(); scopeName != "_default" {
+		return connection.Collection.Bucket().Scope(scopeName).SearchIndexes(), nil
+	}
+	return connection.Cluster.SearchIndexes(), nil
+}
+
+// SearchIndexManagementError maps errors of search index management operations to their search index management error
+func SearchIndexManagementError(err error, indexName string) *search_index_management.SearchIndexManagementError {
+	switch {
+	case errors.Is(err, gocb.ErrIndexNotFound):
+		return search_index_management.NewSearchIndexManagementErrorIndexNotFound(indexName)
+	case errors.Is(err, gocb.ErrInvalidArgument), errors.Is(err, gocb.ErrIndexFailure), errors.Is(err, gocb.ErrFeatureNotAvailable):
+		return search_index_management.NewSearchIndexManagementErrorInvalidArgument(err.Error())
+	case errors.Is(err, gocb.ErrTimeout):
+		return search_index_management.NewSearchIndexManagementErrorTimeout()
+	default:
+		return search_index_management.NewSearchIndexManagementErrorUnexpected(err.Error())
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/sqlpp"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/sqlpp_types"
+)
+
+// Query implements sqlpp.Handler.
+func (h *Handler) Query(ctx context.Context, query string, params []*sqlpp.SqlppValue, options *sqlpp.SqlppQueryOptions) (*wrpc.Result[sqlpp.SqlppQueryHandle, sqlpp.SqlppQueryError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	queryOptions, err := QueryOptions(options, params)
+	if err != nil {
+		h.Logger.Error("Error building query options", "error", err)
+		return wrpc.Err[sqlpp.SqlppQueryHandle](*sqlpp_types.NewSqlppQueryErrorInvalidArgument(err.Error())), nil
+	}
+
+	result, err := connection.query(query, queryOptions)
+	if err != nil {
+		h.Logger.Error("Error executing query", "error", err)
+		return wrpc.Err[sqlpp.SqlppQueryHandle](*SqlppQueryError(err)), nil
+	}
+	handle := h.queryCursors.open(newQueryCursor(sourceId, linkName, result, connection.CursorIdleTimeout))
+	return wrpc.Ok[sqlpp.SqlppQueryError](handle), nil
+}
+
+// NextRows implements sqlpp.Handler.
+func (h *Handler) NextRows(ctx context.Context, handle string, maxRows uint32) (*wrpc.Result[sqlpp.SqlppQueryRows, sqlpp.SqlppQueryError], error) {
+	cursor, err := h.getQueryCursorFromContext(ctx, handle)
+	if err != nil {
+		if errors.Is(err, errQueryCursorNotFound) {
+			return wrpc.Err[sqlpp.SqlppQueryRows](*sqlpp_types.NewSqlppQueryErrorInvalidHandle(handle)), nil
+		}
+		return nil, err
+	}
+	rows, done, err := cursor.next(maxRows)
+	if errors.Is(err, errQueryCursorNotFound) {
+		// The cursor expired after it was looked up
+		return wrpc.Err[sqlpp.SqlppQueryRows](*sqlpp_types.NewSqlppQueryErrorInvalidHandle(handle)), nil
+	}
+	if err != nil {
+		h.Logger.Error("Error reading query rows", "error", err)
+		return wrpc.Err[sqlpp.SqlppQueryRows](*SqlppQueryError(err)), nil
+	}
+	values, err := SqlppValues(rows)
+	if err != nil {
+		h.Logger.Error("Error converting query rows", "error", err)
+		return wrpc.Err[sqlpp.SqlppQueryRows](*sqlpp_types.NewSqlppQueryErrorUnexpected(err.Error())), nil
+	}
+	return wrpc.Ok[sqlpp.SqlppQueryError](sqlpp.SqlppQueryRows{Rows: values, Done: done}), nil
+}
+
+// GetMetadata implements sqlpp.Handler.
+func (h *Handler) GetMetadata(ctx context.Context, handle string) (*wrpc.Result[sqlpp.SqlppQueryMetadata, sqlpp.SqlppQueryError], error) {
+	cursor, err := h.getQueryCursorFromContext(ctx, handle)
+	if err != nil {
+		if errors.Is(err, errQueryCursorNotFound) {
+			return wrpc.Err[sqlpp.SqlppQueryMetadata](*sqlpp_types.NewSqlppQueryErrorInvalidHandle(handle)), nil
+		}
+		return nil, err
+	}
+	metadata, done, err := cursor.metadata()
+	if errors.Is(err, errQueryCursorNotFound) {
+		// The cursor expired after it was looked up
+		return wrpc.Err[sqlpp.SqlppQueryMetadata](*sqlpp_types.NewSqlppQueryErrorInvalidHandle(handle)), nil
+	}
+	if !done {
+		return wrpc.Err[sqlpp.SqlppQueryMetadata](*sqlpp_types.NewSqlppQueryErrorRowsPending()), nil
+	}
+	if err != nil {
+		h.Logger.Error("Error fetching query metadata", "error", err)
+		return wrpc.Err[sqlpp.SqlppQueryMetadata](*SqlppQueryError(err)), nil
+	}
+	return wrpc.Ok[sqlpp.SqlppQueryError](SqlppQueryMetadata(metadata)), nil
+}
+
+// Close implements sqlpp.Handler.
+func (h *Handler) Close(ctx context.Context, handle string) (*wrpc.Result[struct{}, sqlpp.SqlppQueryError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.queryCursors.close(sourceId, linkName, handle); err != nil {
+		if errors.Is(err, errQueryCursorNotFound) {
+			return wrpc.Err[struct{}](*sqlpp_types.NewSqlppQueryErrorInvalidHandle(handle)), nil
+		}
+		// The cursor is removed regardless, the error only concerns the remainder of the stream
+		h.Logger.Warn("Error closing query", "error", err)
+	}
+	return wrpc.Ok[sqlpp.SqlppQueryError](struct{}{}), nil
+}
+
+// Helper function to get a query cursor opened on the link the invocation was made on
+func (h *Handler) getQueryCursorFromContext(ctx context.Context, handle string) (*queryCursor, error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.queryCursors.get(sourceId, linkName, handle)
+}
+
+// query executes a SQL++ statement against the scope configured on the link,
+// falling back to the cluster when the link uses the default scope
+func (c *CouchbaseConnection) query(statement string, options *gocb.QueryOptions) (*gocb.QueryResult, error) {
+	if scopeName := c.Collection.ScopeName(); scopeName != "_default" {
+		return c.Collection.Bucket().Scope(scopeName).Query(statement, options)
+	}
+	return c.Cluster.Query(statement, options)
+}
+
+// SqlppQueryError maps errors returned by the query service to their SQL++ query error
+func SqlppQueryError(err error) *sqlpp.SqlppQueryError {
+	switch {
+	case errors.Is(err, gocb.ErrParsingFailure), errors.Is(err, gocb.ErrPlanningFailure), errors.Is(err, gocb.ErrIndexNotFound):
+		return sqlpp_types.NewSqlppQueryErrorPlanningFailure(err.Error())
+	case errors.Is(err, gocb.ErrTimeout):
+		return sqlpp_types.NewSqlppQueryErrorTimeout()
+	case errors.Is(err, gocb.ErrInvalidArgument):
+		return sqlpp_types.NewSqlppQueryErrorInvalidArgument(err.Error())
+	default:
+		return sqlpp_types.NewSqlppQueryErrorUnexpected(err.Error())
+	}
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/couchbase/gocbcore/v10"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_lookup"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/subdocument_mutate"
+)
+
+// Per-spec results of a lookup, in the order the operations were specified
+type LookupInResults = []*wrpc.Result[subdocument_lookup.LookupInDocument, subdocument_lookup.SubdocumentLookupError]
+type LookupInReplicaResults = []*wrpc.Result[subdocument_lookup.LookupInReplicaDocument, subdocument_lookup.SubdocumentLookupError]
+
+// Lookup implements subdocument_lookup.Handler.
+func (h *Handler) Lookup(ctx context.Context, id string, operations []*subdocument_lookup.LookupOperation, options *subdocument_lookup.LookupOptions) (*wrpc.Result[LookupInResults, subdocument_lookup.LookupError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	specs, err := LookupInSpecs(operations)
+	if err != nil {
+		h.Logger.Error("Error building lookup specs", "error", err)
+		return wrpc.Err[LookupInResults](*subdocument_lookup.NewLookupErrorUnexpected(err.Error())), nil
+	}
+	result, err := collection.LookupIn(id, specs, LookupInOptions(options))
+	if err != nil {
+		h.Logger.Error("Error looking up document", "error", err)
+		return wrpc.Err[LookupInResults](*LookupError(err)), nil
+	}
+	return wrpc.Ok[subdocument_lookup.LookupError](LookupInResult(result, operations)), nil
+}
+
+// LookupInAnyReplicas implements subdocument_lookup.Handler.
+func (h *Handler) LookupInAnyReplicas(ctx context.Context, id string, operations []*subdocument_lookup.LookupOperation, options *subdocument_lookup.LookupOptions) (*wrpc.Result[LookupInReplicaResults, subdocument_lookup.LookupError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	specs, err := LookupInSpecs(operations)
+	if err != nil {
+		h.Logger.Error("Error building lookup specs", "error", err)
+		return wrpc.Err[LookupInReplicaResults](*subdocument_lookup.NewLookupErrorUnexpected(err.Error())), nil
+	}
+	result, err := collection.LookupInAnyReplica(id, specs, LookupInAnyReplicaOptions(options))
+	if err != nil {
+		h.Logger.Error("Error looking up document from any replica", "error", err)
+		return wrpc.Err[LookupInReplicaResults](*LookupError(err)), nil
+	}
+	return wrpc.Ok[subdocument_lookup.LookupError](LookupInReplicaResult(result, operations)), nil
+}
+
+// LookupInAllReplicas implements subdocument_lookup.Handler.
+func (h *Handler) LookupInAllReplicas(ctx context.Context, id string, operations []*subdocument_lookup.LookupOperation, options *subdocument_lookup.LookupOptions) (*wrpc.Result[[]LookupInReplicaResults, subdocument_lookup.LookupError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	specs, err := LookupInSpecs(operations)
+	if err != nil {
+		h.Logger.Error("Error building lookup specs", "error", err)
+		return wrpc.Err[[]LookupInReplicaResults](*subdocument_lookup.NewLookupErrorUnexpected(err.Error())), nil
+	}
+	result, err := collection.LookupInAllReplicas(id, specs, LookupInAllReplicaOptions(options))
+	if err != nil {
+		h.Logger.Error("Error looking up document from all replicas", "error", err)
+		return wrpc.Err[[]LookupInReplicaResults](*LookupError(err)), nil
+	}
+	return wrpc.Ok[subdocument_lookup.LookupError](LookupInAllReplicasResult(result, operations)), nil
+}
+
+// Per-spec results of a mutation, in the order the operations were specified
+type MutateInResults = []*wrpc.Result[subdocument_mutate.MutationResult, subdocument_mutate.SubdocumentMutateError]
+
+// Mutate implements subdocument_mutate.Handler.
+func (h *Handler) Mutate(ctx context.Context, id string, operations []*subdocument_mutate.MutateOperation, options *subdocument_mutate.MutateOptions) (*wrpc.Result[MutateInResults, subdocument_mutate.MutateError], error) {
+	collection, err := h.getCollectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching collection from context", "error", err)
+		return nil, err
+	}
+	specs, err := MutateInSpecs(operations)
+	if err != nil {
+		h.Logger.Error("Error building mutate specs", "error", err)
+		return wrpc.Err[MutateInResults](*subdocument_mutate.NewMutateErrorUnexpected(err.Error())), nil
+	}
+	result, err := collection.MutateIn(id, specs, MutateInOptions(options))
+	if err != nil {
+		h.Logger.Error("Error mutating document", "error", err)
+		var subdocErr gocbcore.SubDocumentError
+		if errors.As(err, &subdocErr) {
+			return wrpc.Ok[subdocument_mutate.MutateError](MutateInSpecErrors(subdocErr, operations)), nil
+		}
+		return wrpc.Err[MutateInResults](*MutateError(err)), nil
+	}
+	return wrpc.Ok[subdocument_mutate.MutateError](MutateInResult(result, operations)), nil
+}
+
+// LookupError maps errors of lookup operations to their lookup error
+func LookupError(err error) *subdocument_lookup.LookupError {
+	if docErr := DocumentError(err); docErr != nil {
+		return subdocument_lookup.NewLookupErrorDocumentError(docErr)
+	}
+	return subdocument_lookup.NewLookupErrorUnexpected(err.Error())
+}
+
+// MutateError maps errors of mutate operations to their mutate error
+func MutateError(err error) *subdocument_mutate.MutateError {
+	if docErr := DocumentError(err); docErr != nil {
+		return subdocument_mutate.NewMutateErrorDocumentError(docErr)
+	}
+	return subdocument_mutate.NewMutateErrorUnexpected(err.Error())
+}
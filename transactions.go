@@ -0,0 +1,424 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/couchbase/gocb/v2"
+	wrpc "wrpc.io/go"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/transactions"
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/wasmcloud/couchbase/types"
+)
+
+// CreateTx implements transactions.Handler.
+func (h *Handler) CreateTx(ctx context.Context, options *transactions.CreateTxOptions) (*wrpc.Result[transactions.TxToken, transactions.CreateTxError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	token := h.transactions.begin(sourceId, linkName, connection, TransactionOptions(options))
+	return wrpc.Ok[transactions.CreateTxError](token), nil
+}
+
+// TxDocumentGet implements transactions.Handler.
+func (h *Handler) TxDocumentGet(ctx context.Context, tx string, collection *types.Collection, id string) (*wrpc.Result[transactions.TxDocumentGetResult, transactions.TxError], error) {
+	openTx, err := h.getTxFromContext(ctx, tx)
+	if err != nil {
+		return txTokenError[transactions.TxDocumentGetResult](err)
+	}
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	txCollection, err := connection.keyspace(collection)
+	if err != nil {
+		h.Logger.Error("Error opening collection", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorUnexpected(err.Error())), nil
+	}
+	doc, metadataId, err := openTx.get(txCollection, id)
+	if err != nil {
+		h.Logger.Error("Error getting document in transaction", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*TxError(err)), nil
+	}
+	result, err := TxDocumentGetResult(doc, metadataId)
+	if err != nil {
+		h.Logger.Error("Error getting document result", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorDocumentError(types.NewDocumentErrorNotJson())), nil
+	}
+	return wrpc.Ok[transactions.TxError](result), nil
+}
+
+// TxDocumentInsert implements transactions.Handler.
+func (h *Handler) TxDocumentInsert(ctx context.Context, tx string, id string, doc *types.Document) (*wrpc.Result[transactions.TxDocumentGetResult, transactions.TxError], error) {
+	openTx, err := h.getTxFromContext(ctx, tx)
+	if err != nil {
+		return txTokenError[transactions.TxDocumentGetResult](err)
+	}
+	value, err := DocumentJSON(doc)
+	if err != nil {
+		h.Logger.Error("Error converting document", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorDocumentError(types.NewDocumentErrorNotJson())), nil
+	}
+	inserted, metadataId, err := openTx.insert(id, value)
+	if err != nil {
+		h.Logger.Error("Error inserting document in transaction", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*TxError(err)), nil
+	}
+	result, err := TxDocumentGetResult(inserted, metadataId)
+	if err != nil {
+		h.Logger.Error("Error getting document result", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorUnexpected(err.Error())), nil
+	}
+	return wrpc.Ok[transactions.TxError](result), nil
+}
+
+// TxDocumentReplace implements transactions.Handler.
+func (h *Handler) TxDocumentReplace(ctx context.Context, tx string, old *transactions.TxDocumentGetResult, doc *types.Document) (*wrpc.Result[transactions.TxDocumentGetResult, transactions.TxError], error) {
+	openTx, err := h.getTxFromContext(ctx, tx)
+	if err != nil {
+		return txTokenError[transactions.TxDocumentGetResult](err)
+	}
+	value, err := DocumentJSON(doc)
+	if err != nil {
+		h.Logger.Error("Error converting document", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorDocumentError(types.NewDocumentErrorNotJson())), nil
+	}
+	replaced, metadataId, err := openTx.replace(old.TxMetadataId, value)
+	if err != nil {
+		h.Logger.Error("Error replacing document in transaction", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*TxError(err)), nil
+	}
+	result, err := TxDocumentGetResult(replaced, metadataId)
+	if err != nil {
+		h.Logger.Error("Error getting document result", "error", err)
+		return wrpc.Err[transactions.TxDocumentGetResult](*transactions.NewTxErrorUnexpected(err.Error())), nil
+	}
+	return wrpc.Ok[transactions.TxError](result), nil
+}
+
+// TxDocumentRemove implements transactions.Handler.
+func (h *Handler) TxDocumentRemove(ctx context.Context, tx string, doc *transactions.TxDocumentGetResult) (*wrpc.Result[struct{}, transactions.TxError], error) {
+	openTx, err := h.getTxFromContext(ctx, tx)
+	if err != nil {
+		return txTokenError[struct{}](err)
+	}
+	if err := openTx.remove(doc.TxMetadataId); err != nil {
+		h.Logger.Error("Error removing document in transaction", "error", err)
+		return wrpc.Err[struct{}](*TxError(err)), nil
+	}
+	return wrpc.Ok[transactions.TxError](struct{}{}), nil
+}
+
+// TxSqlppQuery implements transactions.Handler.
+func (h *Handler) TxSqlppQuery(ctx context.Context, tx string, stmt string, params []*transactions.SqlppValue, options *transactions.TransactionQueryOptions) (*wrpc.Result[transactions.TxSqlppQueryResultToken, transactions.TxError], error) {
+	openTx, err := h.getTxFromContext(ctx, tx)
+	if err != nil {
+		return txTokenError[transactions.TxSqlppQueryResultToken](err)
+	}
+	queryOptions, err := TransactionQueryOptions(options, params)
+	if err != nil {
+		h.Logger.Error("Error building query options", "error", err)
+		return wrpc.Err[transactions.TxSqlppQueryResultToken](*transactions.NewTxErrorUnexpected(err.Error())), nil
+	}
+	result, err := openTx.query(stmt, queryOptions)
+	if err != nil {
+		h.Logger.Error("Error executing query in transaction", "error", err)
+		return wrpc.Err[transactions.TxSqlppQueryResultToken](*TxError(err)), nil
+	}
+	return wrpc.Ok[transactions.TxError](h.transactions.addQuery(openTx, result)), nil
+}
+
+// TxSqlppQueryNext implements transactions.Handler.
+func (h *Handler) TxSqlppQueryNext(ctx context.Context, query string) (*wrpc.Result[*types.Document, transactions.TxError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txQuery, err := h.transactions.getQuery(sourceId, linkName, query)
+	if err != nil {
+		return wrpc.Err[*types.Document](*transactions.NewTxErrorInvalidTx()), nil
+	}
+	row, ok, err := txQuery.next()
+	if err != nil {
+		h.Logger.Error("Error reading transaction query row", "error", err)
+		return wrpc.Err[*types.Document](*TxError(err)), nil
+	}
+	if !ok {
+		return wrpc.Ok[transactions.TxError, *types.Document](nil), nil
+	}
+	return wrpc.Ok[transactions.TxError](types.NewDocumentRaw(string(row))), nil
+}
+
+// TxSqlppQueryGetMetadata implements transactions.Handler.
+func (h *Handler) TxSqlppQueryGetMetadata(ctx context.Context, query string) (*wrpc.Result[transactions.TxSqlppQueryMetadata, transactions.TxError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txQuery, err := h.transactions.getQuery(sourceId, linkName, query)
+	if err != nil {
+		return wrpc.Err[transactions.TxSqlppQueryMetadata](*transactions.NewTxErrorInvalidTx()), nil
+	}
+	metadata, err := txQuery.metadata()
+	if err != nil {
+		h.Logger.Error("Error fetching transaction query metadata", "error", err)
+		return wrpc.Err[transactions.TxSqlppQueryMetadata](*transactions.NewTxErrorUnexpected(err.Error())), nil
+	}
+	return wrpc.Ok[transactions.TxError](TxSqlppQueryMetadata(metadata)), nil
+}
+
+// TxCommit implements transactions.Handler.
+func (h *Handler) TxCommit(ctx context.Context, tx string) (*wrpc.Result[transactions.TxResult, transactions.TxError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := h.transactions.commit(sourceId, linkName, tx)
+	if err != nil {
+		h.Logger.Error("Error committing transaction", "error", err)
+		return wrpc.Err[transactions.TxResult](*TxError(err)), nil
+	}
+	return wrpc.Ok[transactions.TxError](TxResult(result)), nil
+}
+
+// TxRollback implements transactions.Handler.
+func (h *Handler) TxRollback(ctx context.Context, tx string) (*wrpc.Result[struct{}, transactions.TxError], error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.transactions.rollback(sourceId, linkName, tx); err != nil {
+		h.Logger.Error("Error rolling back transaction", "error", err)
+		return wrpc.Err[struct{}](*TxError(err)), nil
+	}
+	return wrpc.Ok[transactions.TxError](struct{}{}), nil
+}
+
+// TxBatch implements transactions.Handler.
+func (h *Handler) TxBatch(ctx context.Context, operations []*transactions.TxBatchOperation, options *transactions.CreateTxOptions) (*wrpc.Result[transactions.TxBatchResult, transactions.TxBatchError], error) {
+	connection, err := h.getConnectionFromContext(ctx)
+	if err != nil {
+		h.Logger.Error("Error fetching connection from context", "error", err)
+		return nil, err
+	}
+	var (
+		results []*transactions.TxBatchOperationResult
+		failed  *uint32
+	)
+	result, err := connection.Cluster.Transactions().Run(func(attempt *gocb.TransactionAttemptContext) error {
+		// Results of a previous attempt are discarded when the transaction is retried
+		results = make([]*transactions.TxBatchOperationResult, 0, len(operations))
+		failed = nil
+		for idx, operation := range operations {
+			opResult, err := connection.txBatchOperation(attempt, operation)
+			if err != nil {
+				index := uint32(idx)
+				failed = &index
+				return err
+			}
+			results = append(results, opResult)
+		}
+		return nil
+	}, TransactionOptions(options))
+	if err != nil {
+		h.Logger.Error("Error running transactional batch", "error", err)
+		return wrpc.Err[transactions.TxBatchResult](TxBatchError(err, failed)), nil
+	}
+	txResult := TxResult(result)
+	return wrpc.Ok[transactions.TxBatchError](transactions.TxBatchResult{
+		Results: results,
+		Tx:      &txResult,
+	}), nil
+}
+
+// txBatchOperation performs an operation of a transactional batch on the attempt context
+func (c *CouchbaseConnection) txBatchOperation(attempt *gocb.TransactionAttemptContext, operation *transactions.TxBatchOperation) (*transactions.TxBatchOperationResult, error) {
+	if operation == nil {
+		return nil, errors.New("operation must not be empty")
+	}
+	switch operation.Discriminant() {
+	case transactions.TxBatchOperationGet:
+		payload, _ := operation.GetGet()
+		collection, err := c.keyspace(payload.Collection)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := attempt.Get(collection, payload.Id)
+		if err != nil {
+			return nil, err
+		}
+		var content json.RawMessage
+		if err := doc.Content(&content); err != nil {
+			return nil, err
+		}
+		return transactions.NewTxBatchOperationResultGet(types.NewDocumentRaw(string(content))), nil
+	case transactions.TxBatchOperationInsert:
+		payload, _ := operation.GetInsert()
+		value, err := DocumentJSON(payload.Document)
+		if err != nil {
+			return nil, err
+		}
+		collection, err := c.keyspace(payload.Collection)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := attempt.Insert(collection, payload.Id, value); err != nil {
+			return nil, err
+		}
+		return transactions.NewTxBatchOperationResultInsert(), nil
+	case transactions.TxBatchOperationReplace:
+		payload, _ := operation.GetReplace()
+		value, err := DocumentJSON(payload.Document)
+		if err != nil {
+			return nil, err
+		}
+		collection, err := c.keyspace(payload.Collection)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := attempt.Get(collection, payload.Id)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := attempt.Replace(doc, value); err != nil {
+			return nil, err
+		}
+		return transactions.NewTxBatchOperationResultReplace(), nil
+	case transactions.TxBatchOperationRemove:
+		payload, _ := operation.GetRemove()
+		collection, err := c.keyspace(payload.Collection)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := attempt.Get(collection, payload.Id)
+		if err != nil {
+			return nil, err
+		}
+		if err := attempt.Remove(doc); err != nil {
+			return nil, err
+		}
+		return transactions.NewTxBatchOperationResultRemove(), nil
+	case transactions.TxBatchOperationQuery:
+		payload, _ := operation.GetQuery()
+		options, err := TransactionQueryOptions(payload.Options, payload.Params)
+		if err != nil {
+			return nil, err
+		}
+		options.Scope = txQueryScope(c.Collection)
+		result, err := attempt.Query(payload.Stmt, options)
+		if err != nil {
+			return nil, err
+		}
+		rows := []*types.Document{}
+		for result.Next() {
+			var row json.RawMessage
+			if err := result.Row(&row); err != nil {
+				return nil, err
+			}
+			rows = append(rows, types.NewDocumentRaw(string(row)))
+		}
+		return transactions.NewTxBatchOperationResultQuery(rows), nil
+	default:
+		return nil, fmt.Errorf("unsupported transactional batch operation %s", operation)
+	}
+}
+
+// Helper function to get a transaction started on the link the invocation was made on
+func (h *Handler) getTxFromContext(ctx context.Context, token string) (*openTx, error) {
+	sourceId, linkName, err := h.getLinkFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.transactions.get(sourceId, linkName, token)
+}
+
+// txTokenError returns unknown transactions to the caller, while other errors fail the invocation
+func txTokenError[T any](err error) (*wrpc.Result[T, transactions.TxError], error) {
+	if errors.Is(err, errTxNotFound) {
+		return wrpc.Err[T](*transactions.NewTxErrorInvalidTx()), nil
+	}
+	return nil, err
+}
+
+var errBucketNotLinked = errors.New("bucket is not the bucket configured on the link")
+
+// keyspace returns a collection of the bucket configured on the link, defaulting to the default scope,
+// or the collection configured on the link if none is specified
+func (c *CouchbaseConnection) keyspace(collection *types.Collection) (*gocb.Collection, error) {
+	if collection == nil {
+		return c.Collection, nil
+	}
+	if bucketName := c.Collection.Bucket().Name(); collection.Bucket != bucketName {
+		return nil, fmt.Errorf("%w: %s (expected %s)", errBucketNotLinked, collection.Bucket, bucketName)
+	}
+	scopeName := "_default"
+	if collection.Scope != nil {
+		scopeName = *collection.Scope
+	}
+	return c.lease.bucket(collection.Bucket).Scope(scopeName).Collection(collection.Name), nil
+}
+
+// txQueryScope returns the scope queries run against inside transactions, like the sqlpp interface
+func txQueryScope(collection *gocb.Collection) *gocb.Scope {
+	if scopeName := collection.ScopeName(); scopeName != "_default" {
+		return collection.Bucket().Scope(scopeName)
+	}
+	return nil
+}
+
+// TxError maps errors returned by transaction operations to their transaction error
+func TxError(err error) *transactions.TxError {
+	var (
+		opErr         *gocb.TransactionOperationFailedError
+		expiredErr    *gocb.TransactionExpiredError
+		postCommitErr *gocb.TransactionFailedPostCommit
+		ambiguousErr  *gocb.TransactionCommitAmbiguousError
+		failedErr     *gocb.TransactionFailedError
+	)
+	// Operation failures only expose their cause through InternalUnwrap
+	if errors.As(err, &opErr) {
+		if cause := opErr.InternalUnwrap(); cause != nil && DocumentError(cause) != nil {
+			return transactions.NewTxErrorDocumentError(DocumentError(cause))
+		}
+		return transactions.NewTxErrorFailed()
+	}
+	switch {
+	case errors.Is(err, errTxNotFound), errors.Is(err, errTxQueryNotFound):
+		return transactions.NewTxErrorInvalidTx()
+	case errors.As(err, &expiredErr), errors.Is(err, gocb.ErrAttemptExpired):
+		return transactions.NewTxErrorExpired()
+	case errors.As(err, &postCommitErr):
+		return transactions.NewTxErrorFailedPostCommit()
+	case errors.As(err, &ambiguousErr):
+		return transactions.NewTxErrorAmbiguous()
+	case errors.As(err, &failedErr), errors.Is(err, gocb.ErrPreviousOperationFailed):
+		return transactions.NewTxErrorFailed()
+	case DocumentError(err) != nil:
+		return transactions.NewTxErrorDocumentError(DocumentError(err))
+	default:
+		return transactions.NewTxErrorUnexpected(err.Error())
+	}
+}
+
+// TxBatchError maps the error of a transactional batch, reporting why the failing operation failed
+func TxBatchError(err error, operation *uint32) transactions.TxBatchError {
+	txErr := TxError(err)
+	// The cause of a failed transaction is the error of the failing operation
+	var failedErr *gocb.TransactionFailedError
+	if operation != nil && errors.As(err, &failedErr) {
+		if docErr := DocumentError(err); docErr != nil {
+			txErr = transactions.NewTxErrorDocumentError(docErr)
+		}
+	}
+	return transactions.TxBatchError{Operation: operation, Error: txErr}
+}
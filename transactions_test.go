@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/couchbase/gocb/v2"
+
+	// Generated bindings
+	"github.com/couchbase-examples/wasmcloud-provider-couchbase/bindings/exports/wasmcloud/couchbase/transactions"
+)
+
+func TestTxError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected transactions.TxErrorDiscriminant
+	}{
+		{"unknown token", errTxNotFound, transactions.TxErrorInvalidTx},
+		{"unknown query token", errTxQueryNotFound, transactions.TxErrorInvalidTx},
+		{"expired", &gocb.TransactionExpiredError{}, transactions.TxErrorExpired},
+		{"attempt expired", fmt.Errorf("commit: %w", gocb.ErrAttemptExpired), transactions.TxErrorExpired},
+		{"failed", &gocb.TransactionFailedError{}, transactions.TxErrorFailed},
+		{"failed post commit", &gocb.TransactionFailedPostCommit{}, transactions.TxErrorFailedPostCommit},
+		{"operation failed", &gocb.TransactionOperationFailedError{}, transactions.TxErrorFailed},
+		{"previous operation failed", gocb.ErrPreviousOperationFailed, transactions.TxErrorFailed},
+		{"document not found", gocb.ErrDocumentNotFound, transactions.TxErrorDocumentError},
+		{"untracked document", errTxDocumentNotTracked, transactions.TxErrorUnexpected},
+		{"unexpected", errors.New("boom"), transactions.TxErrorUnexpected},
+	}
+
+	for _, test := range tests {
+		if actual := TxError(test.err).Discriminant(); actual != test.expected {
+			t.Errorf("expected %s error to map to %v, got %v", test.name, test.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/couchbase/gocb/v2"
+	"github.com/google/uuid"
+)
+
+// How often abandoned transactions are looked for
+const txReapInterval = 10 * time.Second
+
+var (
+	errTxNotFound      = errors.New("transaction does not exist")
+	errTxQueryNotFound = errors.New("transaction query does not exist")
+	// Replaced and removed documents must have been retrieved (or staged) by the same transaction
+	errTxDocumentNotTracked = errors.New("document was not retrieved in this transaction")
+	// Operations are driven by separate invocations and cannot be replayed on a new attempt
+	errTxAttemptRetried = errors.New("transaction attempt cannot be retried")
+	// Returned from the lambda to roll back the transaction
+	errTxRollback = errors.New("transaction rolled back")
+)
+
+// A transaction driven by successive invocations of a linked component
+//
+// gocb transactions are lambda based: the lambda runs on its own goroutine for
+// the lifetime of the transaction, executing operations submitted by handlers
+// on the attempt context until the transaction is committed or fails.
+type openTx struct {
+	sourceId string
+	linkName string
+
+	// Collection used for inserts and queries
+	collection *gocb.Collection
+
+	// Serializes submitted operations, so that a transaction is only expired while idle
+	mu          sync.Mutex
+	lastUsed    time.Time
+	idleTimeout time.Duration
+
+	ops      chan *txOp
+	finished chan struct{}
+	// Set once the lambda returned, before finished is closed
+	result *gocb.TransactionResult
+	err    error
+
+	// Only accessed from the lambda goroutine
+	documents map[string]*gocb.TransactionGetResult
+	// The commit or rollback that ended the lambda, replied to once the transaction completed
+	ending *txOp
+}
+
+// An operation submitted to the lambda of a transaction
+type txOp struct {
+	// Runs on the attempt context, a nil function commits the transaction
+	run  func(*gocb.TransactionAttemptContext) error
+	done chan error
+}
+
+// The buffered result of a query performed inside a transaction
+type txQuery struct {
+	tx     *openTx
+	mu     sync.Mutex
+	result *gocb.TransactionQueryResult
+}
+
+// Registry of the transactions opened by linked components
+type txRegistry struct {
+	mu      sync.Mutex
+	txs     map[string]*openTx
+	queries map[string]*txQuery
+}
+
+func newTxRegistry() *txRegistry {
+	return &txRegistry{
+		txs:     make(map[string]*openTx),
+		queries: make(map[string]*txQuery),
+	}
+}
+
+// begin starts a transaction on the given link, returning the token identifying it
+func (r *txRegistry) begin(sourceId, linkName string, connection *CouchbaseConnection, options *gocb.TransactionOptions) string {
+	tx := &openTx{
+		sourceId:    sourceId,
+		linkName:    linkName,
+		collection:  connection.Collection,
+		lastUsed:    time.Now(),
+		idleTimeout: connection.TransactionIdleTimeout,
+		ops:         make(chan *txOp),
+		finished:    make(chan struct{}),
+		documents:   make(map[string]*gocb.TransactionGetResult),
+	}
+	go tx.run(connection.Cluster.Transactions(), options)
+
+	token := uuid.NewString()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.txs[token] = tx
+	return token
+}
+
+// get returns the transaction for a token, only if it was started on the given link
+func (r *txRegistry) get(sourceId, linkName, token string) (*openTx, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	tx, ok := r.txs[token]
+	if !ok || tx.sourceId != sourceId || tx.linkName != linkName {
+		return nil, errTxNotFound
+	}
+	return tx, nil
+}
+
+// commit commits a transaction, which is removed from the registry whatever the outcome
+func (r *txRegistry) commit(sourceId, linkName, token string) (*gocb.TransactionResult, error) {
+	tx, err := r.get(sourceId, linkName, token)
+	if err != nil {
+		return nil, err
+	}
+	r.remove(token, tx)
+	if err := tx.submit(nil); err != nil {
+		return nil, err
+	}
+	return tx.result, nil
+}
+
+// rollback rolls back a transaction, which is removed from the registry whatever the outcome
+func (r *txRegistry) rollback(sourceId, linkName, token string) error {
+	tx, err := r.get(sourceId, linkName, token)
+	if err != nil {
+		return err
+	}
+	r.remove(token, tx)
+	return tx.rollback()
+}
+
+// rollbackLink rolls back every transaction that was started on a link
+func (r *txRegistry) rollbackLink(sourceId, linkName string) {
+	r.rollbackMatching(func(tx *openTx) bool {
+		return tx.sourceId == sourceId && tx.linkName == linkName
+	})
+}
+
+// rollbackAll rolls back every open transaction
+func (r *txRegistry) rollbackAll() {
+	r.rollbackMatching(func(*openTx) bool { return true })
+}
+
+// expire rolls back every transaction that has been idle for longer than its link allows
+func (r *txRegistry) expire(now time.Time) int {
+	return r.rollbackMatching(func(tx *openTx) bool {
+		return tx.expired(now)
+	})
+}
+
+func (r *txRegistry) rollbackMatching(match func(*openTx) bool) int {
+	r.mu.Lock()
+	var rollingBack []*openTx
+	for token, tx := range r.txs {
+		if match(tx) {
+			rollingBack = append(rollingBack, tx)
+			r.removeLocked(token, tx)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, tx := range rollingBack {
+		_ = tx.rollback()
+	}
+	return len(rollingBack)
+}
+
+// reap periodically rolls back abandoned transactions until the context is cancelled
+func (r *txRegistry) reap(ctx context.Context, onExpired func(count int)) {
+	ticker := time.NewTicker(txReapInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if count := r.expire(now); count > 0 {
+				onExpired(count)
+			}
+		}
+	}
+}
+
+// remove forgets a transaction along with the results of its queries
+func (r *txRegistry) remove(token string, tx *openTx) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.removeLocked(token, tx)
+}
+
+func (r *txRegistry) removeLocked(token string, tx *openTx) {
+	delete(r.txs, token)
+	for queryToken, query := range r.queries {
+		if query.tx == tx {
+			delete(r.queries, queryToken)
+		}
+	}
+}
+
+// addQuery registers the result of a query performed inside a transaction, returning the token identifying it
+func (r *txRegistry) addQuery(tx *openTx, result *gocb.TransactionQueryResult) string {
+	token := uuid.NewString()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queries[token] = &txQuery{tx: tx, result: result}
+	return token
+}
+
+// getQuery returns the query for a token, only if its transaction was started on the given link
+func (r *txRegistry) getQuery(sourceId, linkName, token string) (*txQuery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	query, ok := r.queries[token]
+	if !ok || query.tx.sourceId != sourceId || query.tx.linkName != linkName {
+		return nil, errTxQueryNotFound
+	}
+	return query, nil
+}
+
+func (tx *openTx) run(transactions *gocb.Transactions, options *gocb.TransactionOptions) {
+	defer close(tx.finished)
+	attempts := 0
+	tx.result, tx.err = transactions.Run(func(ctx *gocb.TransactionAttemptContext) error {
+		if attempts++; attempts > 1 {
+			return errTxAttemptRetried
+		}
+		for op := range tx.ops {
+			if op.run == nil {
+				tx.ending = op
+				return nil
+			}
+			err := op.run(ctx)
+			if errors.Is(err, errTxRollback) {
+				tx.ending = op
+				return err
+			}
+			op.done <- err
+			// Errors that failed the attempt end the transaction, others (ex. a missing document) may be handled by the caller
+			var opErr *gocb.TransactionOperationFailedError
+			if errors.As(err, &opErr) {
+				return err
+			}
+		}
+		return nil
+	}, options)
+	if tx.ending != nil {
+		tx.ending.done <- tx.err
+	}
+}
+
+// submit runs an operation inside the transaction, failing if the transaction already ended
+func (tx *openTx) submit(run func(*gocb.TransactionAttemptContext) error) error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	defer func() { tx.lastUsed = time.Now() }()
+
+	op := &txOp{run: run, done: make(chan error, 1)}
+	select {
+	case tx.ops <- op:
+		return <-op.done
+	case <-tx.finished:
+		if tx.err != nil {
+			return tx.err
+		}
+		return errTxNotFound
+	}
+}
+
+// rollback ends the transaction, discarding its staged writes
+func (tx *openTx) rollback() error {
+	err := tx.submit(func(*gocb.TransactionAttemptContext) error {
+		return errTxRollback
+	})
+	if errors.Is(err, errTxRollback) {
+		return nil
+	}
+	return err
+}
+
+func (tx *openTx) expired(now time.Time) bool {
+	// A transaction that is running an operation is in use, regardless of when it was last used
+	if !tx.mu.TryLock() {
+		return false
+	}
+	defer tx.mu.Unlock()
+	return now.Sub(tx.lastUsed) > tx.idleTimeout
+}
+
+// get retrieves a document, returning the ID under which its transaction metadata is kept
+func (tx *openTx) get(collection *gocb.Collection, id string) (doc *gocb.TransactionGetResult, metadataId string, err error) {
+	err = tx.submit(func(ctx *gocb.TransactionAttemptContext) error {
+		doc, err = ctx.Get(collection, id)
+		if err != nil {
+			return err
+		}
+		metadataId = tx.track(doc)
+		return nil
+	})
+	return doc, metadataId, err
+}
+
+// insert stages the insertion of a document into the collection of the link
+func (tx *openTx) insert(id string, value json.RawMessage) (doc *gocb.TransactionGetResult, metadataId string, err error) {
+	err = tx.submit(func(ctx *gocb.TransactionAttemptContext) error {
+		doc, err = ctx.Insert(tx.collection, id, value)
+		if err != nil {
+			return err
+		}
+		metadataId = tx.track(doc)
+		return nil
+	})
+	return doc, metadataId, err
+}
+
+// replace stages the replacement of a document previously retrieved in the transaction
+func (tx *openTx) replace(oldMetadataId string, value json.RawMessage) (doc *gocb.TransactionGetResult, metadataId string, err error) {
+	err = tx.submit(func(ctx *gocb.TransactionAttemptContext) error {
+		old, ok := tx.documents[oldMetadataId]
+		if !ok {
+			return errTxDocumentNotTracked
+		}
+		doc, err = ctx.Replace(old, value)
+		if err != nil {
+			return err
+		}
+		metadataId = tx.track(doc)
+		return nil
+	})
+	return doc, metadataId, err
+}
+
+// remove stages the removal of a document previously retrieved in the transaction
+func (tx *openTx) remove(metadataId string) error {
+	return tx.submit(func(ctx *gocb.TransactionAttemptContext) error {
+		doc, ok := tx.documents[metadataId]
+		if !ok {
+			return errTxDocumentNotTracked
+		}
+		return ctx.Remove(doc)
+	})
+}
+
+// query performs a SQL++ query inside the transaction, buffering all of its rows
+func (tx *openTx) query(statement string, options *gocb.TransactionQueryOptions) (result *gocb.TransactionQueryResult, err error) {
+	options.Scope = txQueryScope(tx.collection)
+	err = tx.submit(func(ctx *gocb.TransactionAttemptContext) error {
+		result, err = ctx.Query(statement, options)
+		return err
+	})
+	return result, err
+}
+
+// track keeps the transaction metadata of a document, so it can later be replaced or removed
+func (tx *openTx) track(doc *gocb.TransactionGetResult) string {
+	metadataId := uuid.NewString()
+	tx.documents[metadataId] = doc
+	return metadataId
+}
+
+// next reads the next row of the query, if any
+func (q *txQuery) next() (json.RawMessage, bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.result.Next() {
+		return nil, false, nil
+	}
+	var row json.RawMessage
+	if err := q.result.Row(&row); err != nil {
+		return nil, false, err
+	}
+	return row, true, nil
+}
+
+func (q *txQuery) metadata() (*gocb.QueryMetaData, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.result.MetaData()
+}